@@ -87,6 +87,10 @@ func (a *App) Layout(gtx layout.Context, e app.FrameEvent) layout.Dimensions {
 		gtx = gtx.Disabled()
 	}
 
+	a.SyncVolumeEnvelope()
+	a.SyncStoredPeaks()
+	a.SyncDecodedSamples()
+	a.SyncOutputStall()
 	switch a.selectedTab {
 	case Project:
 		a.projectView.Layout(gtx)
@@ -0,0 +1,188 @@
+package align
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/spyhere/re-peat/internal/dsp"
+)
+
+var ErrTooShort = errors.New("audio is too short to align")
+
+const (
+	envelopeRate  = 1000 // rate of the envelopes compared on the coarse pass
+	searchSeconds = 90   // how much of the original is compared on the coarse pass
+	refineSeconds = 5    // how much of the original is compared sample by sample
+	minRefineBins = 16   // refine window should cover at least this many envelope bins
+)
+
+type Result struct {
+	Offset     int     // samples to add to a position in the original to get it in the moved audio
+	Confidence float64 // normalised correlation at the offset, 0..1
+}
+
+// How many leading frames of each recording Offset looks at
+func Frames(sampleRate, maxOffset int) int {
+	return searchSeconds*sampleRate + maxOffset
+}
+
+// Finds where the content of "orig" starts in "moved", both mono with the same sample rate.
+// First envelopes are cross-correlated to find the rough lag, then it is refined on the samples.
+func Offset(ctx context.Context, orig, moved []float32, sampleRate, maxOffset int) (Result, error) {
+	if sampleRate <= 0 {
+		return Result{}, fmt.Errorf("align: invalid sample rate %d", sampleRate)
+	}
+	if len(orig) < sampleRate || len(moved) < sampleRate {
+		return Result{}, ErrTooShort
+	}
+	factor := max(1, sampleRate/envelopeRate)
+	origEnv := envelope(orig[:min(len(orig), searchSeconds*sampleRate)], factor)
+	movedEnv := envelope(moved[:min(len(moved), Frames(sampleRate, maxOffset))], factor)
+	lag, err := correlate(ctx, origEnv, movedEnv, maxOffset/factor)
+	if err != nil {
+		return Result{}, err
+	}
+	return refine(ctx, orig, moved, refineProps{
+		lo:     max(-maxOffset, (lag-2)*factor),
+		hi:     min(maxOffset, (lag+2)*factor),
+		window: refineSeconds * sampleRate,
+		factor: factor,
+		env:    origEnv,
+	})
+}
+
+// Mean absolute value of every "factor" samples
+func envelope(samples []float32, factor int) []float64 {
+	env := make([]float64, len(samples)/factor)
+	for i := range env {
+		sum := 0.0
+		for _, it := range samples[i*factor : (i+1)*factor] {
+			sum += math.Abs(float64(it))
+		}
+		env[i] = sum / float64(factor)
+	}
+	return env
+}
+
+// Lag in -maxLag..maxLag at which "b" matches "a" best, using FFT cross-correlation of mean removed signals
+func correlate(ctx context.Context, a, b []float64, maxLag int) (int, error) {
+	n := dsp.NextPow2(len(a) + len(b))
+	fa := centered(a, n)
+	fb := centered(b, n)
+	f := dsp.NewFFT(n)
+	f.Transform(fa)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	f.Transform(fb)
+	for i := range fa {
+		fa[i] = cmplx.Conj(fa[i]) * fb[i]
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	f.Inverse(fa)
+
+	// Negative lags are wrapped to the end of the buffer
+	best, bestV := 0, math.Inf(-1)
+	for lag := -min(maxLag, len(a)-1); lag <= min(maxLag, len(b)-1); lag++ {
+		v := real(fa[(lag+n)%n])
+		if v > bestV {
+			best, bestV = lag, v
+		}
+	}
+	return best, nil
+}
+
+func centered(s []float64, size int) []complex128 {
+	mean := 0.0
+	for _, it := range s {
+		mean += it
+	}
+	mean /= float64(len(s))
+	buf := make([]complex128, size)
+	for i, it := range s {
+		buf[i] = complex(it-mean, 0)
+	}
+	return buf
+}
+
+type refineProps struct {
+	lo, hi int // lag range to check
+	window int
+	factor int
+	env    []float64 // envelope of the original, to pick the loudest window
+}
+
+func refine(ctx context.Context, orig, moved []float32, p refineProps) (Result, error) {
+	// Window has to fit into both recordings for every checked lag
+	from := max(0, -p.lo)
+	to := min(len(orig), len(moved)-p.hi)
+	window := min(p.window, to-from)
+	if window < minRefineBins*p.factor {
+		return Result{}, ErrTooShort
+	}
+	start := loudestWindow(p.env, from/p.factor, (to-window)/p.factor, window/p.factor) * p.factor
+	start = clamp(start, from, to-window)
+
+	a := orig[start : start+window]
+	energyA := energy(a)
+	if energyA == 0 {
+		return Result{Offset: p.lo + (p.hi-p.lo)/2}, nil
+	}
+	best := Result{Offset: p.lo, Confidence: math.Inf(-1)}
+	for lag := p.lo; lag <= p.hi; lag++ {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		b := moved[start+lag : start+lag+window]
+		energyB := energy(b)
+		if energyB == 0 {
+			continue
+		}
+		dot := 0.0
+		for i, it := range a {
+			dot += float64(it) * float64(b[i])
+		}
+		if score := dot / math.Sqrt(energyA*energyB); score > best.Confidence {
+			best = Result{Offset: lag, Confidence: score}
+		}
+	}
+	best.Confidence = max(0, best.Confidence)
+	return best, nil
+}
+
+// Start of the window with the highest envelope sum, in bins between "from" and "to"
+func loudestWindow(env []float64, from, to, size int) int {
+	to = min(to, len(env)-size)
+	if to <= from {
+		return from
+	}
+	sum := 0.0
+	for _, it := range env[from : from+size] {
+		sum += it
+	}
+	best, bestSum := from, sum
+	for i := from + 1; i <= to; i++ {
+		sum += env[i+size-1] - env[i-1]
+		if sum > bestSum {
+			best, bestSum = i, sum
+		}
+	}
+	return best
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+func energy(s []float32) float64 {
+	sum := 0.0
+	for _, it := range s {
+		sum += float64(it) * float64(it)
+	}
+	return sum
+}
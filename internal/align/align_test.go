@@ -0,0 +1,71 @@
+package align
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const testRate = 8000
+
+// Noise with a slowly changing loudness, so envelopes have something to match
+func testSignal(seed int64, seconds float64) []float32 {
+	rnd := rand.New(rand.NewSource(seed))
+	s := make([]float32, int(seconds*testRate))
+	for i := range s {
+		gain := 0.5 + 0.4*math.Sin(float64(i)/testRate*2*math.Pi*0.7)*math.Sin(float64(i)/testRate*2*math.Pi*0.13)
+		s[i] = float32(gain * (rnd.Float64()*2 - 1))
+	}
+	return s
+}
+
+func shifted(s []float32, offset int) []float32 {
+	if offset < 0 {
+		return s[-offset:]
+	}
+	return append(make([]float32, offset), s...)
+}
+
+func TestOffsetFindsShift(t *testing.T) {
+	orig := testSignal(1, 20)
+	for _, offset := range []int{0, 1234, -777, testRate * 3} {
+		res, err := Offset(context.Background(), orig, shifted(orig, offset), testRate, testRate*5)
+		if err != nil {
+			t.Fatalf("offset %d: %v", offset, err)
+		}
+		if res.Offset != offset {
+			t.Errorf("expected offset %d, got %d", offset, res.Offset)
+		}
+		if res.Confidence < 0.99 {
+			t.Errorf("offset %d: expected confident match, got %f", offset, res.Confidence)
+		}
+	}
+}
+
+func TestOffsetUnrelatedAudio(t *testing.T) {
+	res, err := Offset(context.Background(), testSignal(1, 20), testSignal(2, 20), testRate, testRate*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Confidence > 0.2 {
+		t.Errorf("expected low confidence for unrelated audio, got %f", res.Confidence)
+	}
+}
+
+func TestOffsetTooShort(t *testing.T) {
+	_, err := Offset(context.Background(), testSignal(1, 0.5), testSignal(1, 20), testRate, testRate)
+	if !errors.Is(err, ErrTooShort) {
+		t.Errorf("expected ErrTooShort, got %v", err)
+	}
+}
+
+func TestOffsetCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	orig := testSignal(1, 10)
+	if _, err := Offset(ctx, orig, orig, testRate, testRate); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/beep"
+)
+
+var errAIFF = errors.New("malformed AIFF file")
+
+// Decodes uncompressed AIFF and AIFF-C (NONE, sowt, fl32 and fl64 compression types)
+func decodeAIFF(r io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	var form struct {
+		ID   [4]byte
+		Size uint32
+		Type [4]byte
+	}
+	if err := binary.Read(r, binary.BigEndian, &form); err != nil {
+		return nil, beep.Format{}, err
+	}
+	if string(form.ID[:]) != "FORM" || (string(form.Type[:]) != "AIFF" && string(form.Type[:]) != "AIFC") {
+		return nil, beep.Format{}, errAIFF
+	}
+	isAIFC := string(form.Type[:]) == "AIFC"
+
+	d := &aiffDecoder{r: r, order: binary.BigEndian}
+	var hasComm, hasData bool
+	for !hasComm || !hasData {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.BigEndian, &chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, beep.Format{}, io.ErrUnexpectedEOF
+			}
+			return nil, beep.Format{}, err
+		}
+		// Chunks are padded to an even size
+		next := int64(chunk.Size) + int64(chunk.Size&1)
+		switch string(chunk.ID[:]) {
+		case "COMM":
+			read, err := d.readComm(r, isAIFC)
+			if err != nil {
+				return nil, beep.Format{}, err
+			}
+			next -= read
+			hasComm = true
+		case "SSND":
+			var ssnd struct {
+				Offset    uint32
+				BlockSize uint32
+			}
+			if err := binary.Read(r, binary.BigEndian, &ssnd); err != nil {
+				return nil, beep.Format{}, err
+			}
+			start, err := r.Seek(int64(ssnd.Offset), io.SeekCurrent)
+			if err != nil {
+				return nil, beep.Format{}, err
+			}
+			d.dataStart = start
+			d.dataSize = int64(chunk.Size) - 8 - int64(ssnd.Offset)
+			hasData = true
+			next = 0
+			if !hasComm {
+				// Sound data may come first, so it has to be skipped to find COMM
+				next = int64(chunk.Size) - 8 + int64(chunk.Size&1) - int64(ssnd.Offset)
+			}
+		}
+		if next > 0 {
+			if _, err := r.Seek(next, io.SeekCurrent); err != nil {
+				return nil, beep.Format{}, err
+			}
+		}
+	}
+	if d.channels < 1 {
+		return nil, beep.Format{}, &DecodeError{Kind: ErrKindChannels, Format: FormatAIFF, Channels: d.channels}
+	}
+	// Integer samples fit into 32 bits, float ones are 32 or 64 bits
+	validSize := d.bytesPerSample >= 1 && d.bytesPerSample <= 4
+	if d.isFloat {
+		validSize = d.bytesPerSample == 4 || d.bytesPerSample == 8
+	}
+	// Fractional rates turn into 0 Hz, which the resampler can't take. NaN fails the comparison too
+	validRate := d.sampleRate >= 1 && d.sampleRate <= math.MaxInt32
+	if !validSize || !validRate || d.dataSize < 0 {
+		return nil, beep.Format{}, errAIFF
+	}
+	d.bytesPerFrame = d.bytesPerSample * d.channels
+	d.frames = min(d.frames, int(d.dataSize)/d.bytesPerFrame)
+	if _, err := r.Seek(d.dataStart, io.SeekStart); err != nil {
+		return nil, beep.Format{}, err
+	}
+	format := beep.Format{
+		SampleRate:  beep.SampleRate(d.sampleRate),
+		NumChannels: d.channels,
+		Precision:   min(d.bytesPerSample, 3),
+	}
+	return d, format, nil
+}
+
+type aiffDecoder struct {
+	r              io.ReadSeekCloser
+	order          binary.ByteOrder
+	isFloat        bool
+	channels       int
+	bytesPerSample int
+	bytesPerFrame  int
+	sampleRate     float64
+	frames         int
+	dataStart      int64
+	dataSize       int64
+	pos            int
+	buf            []byte
+	err            error
+}
+
+// Returns amount of bytes read from the chunk
+func (d *aiffDecoder) readComm(r io.Reader, isAIFC bool) (int64, error) {
+	var comm struct {
+		Channels   int16
+		Frames     uint32
+		SampleSize int16
+		Rate       [10]byte
+	}
+	if err := binary.Read(r, binary.BigEndian, &comm); err != nil {
+		return 0, err
+	}
+	read := int64(18)
+	d.channels = int(comm.Channels)
+	d.frames = int(comm.Frames)
+	d.bytesPerSample = (int(comm.SampleSize) + 7) / 8
+	d.sampleRate = extendedToFloat(comm.Rate)
+	if !isAIFC {
+		return read, nil
+	}
+	var compression [4]byte
+	if _, err := io.ReadFull(r, compression[:]); err != nil {
+		return 0, err
+	}
+	read += 4
+	switch string(compression[:]) {
+	case "NONE", "twos":
+	case "sowt":
+		d.order = binary.LittleEndian
+	case "fl32", "FL32":
+		d.isFloat = true
+		d.bytesPerSample = 4
+	case "fl64", "FL64":
+		d.isFloat = true
+		d.bytesPerSample = 8
+	default:
+		return 0, fmt.Errorf("%w: AIFF-C compression %q", ErrUnsupportedFormat, compression[:])
+	}
+	return read, nil
+}
+
+// 80 bit IEEE 754 extended precision number, used by AIFF for the sample rate
+func extendedToFloat(b [10]byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]))
+	mant := binary.BigEndian.Uint64(b[2:10])
+	sign := 1.0
+	if exp&0x8000 != 0 {
+		sign = -1
+		exp &= 0x7fff
+	}
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+	return sign * math.Ldexp(float64(mant), exp-16383-63)
+}
+
+func (d *aiffDecoder) sample(p []byte) float64 {
+	if d.isFloat {
+		if d.bytesPerSample == 8 {
+			return math.Float64frombits(d.order.Uint64(p))
+		}
+		return float64(math.Float32frombits(d.order.Uint32(p)))
+	}
+	// Left aligned into 32 bits, so sign comes for free
+	var v int32
+	switch d.bytesPerSample {
+	case 1:
+		v = int32(p[0]) << 24
+	case 2:
+		v = int32(d.order.Uint16(p)) << 16
+	case 3:
+		if d.order == binary.BigEndian {
+			v = int32(p[0])<<24 | int32(p[1])<<16 | int32(p[2])<<8
+		} else {
+			v = int32(p[2])<<24 | int32(p[1])<<16 | int32(p[0])<<8
+		}
+	default:
+		v = int32(d.order.Uint32(p))
+	}
+	return float64(v) / (1 << 31)
+}
+
+func (d *aiffDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil || d.pos >= d.frames {
+		return 0, false
+	}
+	frames := min(len(samples), d.frames-d.pos)
+	size := frames * d.bytesPerFrame
+	if cap(d.buf) < size {
+		d.buf = make([]byte, size)
+	}
+	p := d.buf[:size]
+	read, err := io.ReadFull(d.r, p)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		d.err = err
+		return 0, false
+	}
+	n = read / d.bytesPerFrame
+	for i := range n {
+		frame := p[i*d.bytesPerFrame:]
+		samples[i][0] = d.sample(frame)
+		if d.channels > 1 {
+			samples[i][1] = d.sample(frame[d.bytesPerSample:])
+		} else {
+			samples[i][1] = samples[i][0]
+		}
+	}
+	d.pos += n
+	if n == 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func (d *aiffDecoder) Err() error {
+	return d.err
+}
+
+func (d *aiffDecoder) Len() int {
+	return d.frames
+}
+
+func (d *aiffDecoder) Position() int {
+	return d.pos
+}
+
+func (d *aiffDecoder) Seek(p int) error {
+	if p < 0 || p > d.frames {
+		return fmt.Errorf("aiff: seek position %v out of range [%v, %v]", p, 0, d.frames)
+	}
+	if _, err := d.r.Seek(d.dataStart+int64(p*d.bytesPerFrame), io.SeekStart); err != nil {
+		return err
+	}
+	d.pos = p
+	return nil
+}
+
+func (d *aiffDecoder) Close() error {
+	return d.r.Close()
+}
@@ -56,7 +56,7 @@ func (a AudioMeta) ChannelsString(i18n i18n.State) string {
 	case 2:
 		return i18n.Generic.Stereo
 	default:
-		panic("unreachable")
+		return fmt.Sprintf(i18n.Generic.MultiChannel, a.Channels)
 	}
 }
 
@@ -1,9 +1,13 @@
 package audio
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gopxl/beep"
 	"github.com/gopxl/beep/flac"
@@ -11,41 +15,168 @@ import (
 	"github.com/gopxl/beep/wav"
 )
 
-func Decode(f *os.File) (beep.StreamSeekCloser, beep.Format, error) {
-	var (
-		streamer beep.StreamSeekCloser
-		format   beep.Format
-		err      error
-	)
-	switch filepath.Ext(f.Name()) {
-	case ".mp3":
-		streamer, format, err = mp3.Decode(f)
-	case ".wav":
-		streamer, format, err = wav.Decode(f)
-	case ".flac":
-		streamer, format, err = flac.Decode(f)
+var ErrUnsupportedFormat = errors.New("unsupported audio format")
+
+// Extensions offered by the file chooser
+var Extensions = []string{".mp3", ".wav", ".flac", ".aiff", ".aif", ".aifc", ".ogg", ".oga"}
+
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatMP3
+	FormatWAV
+	FormatFLAC
+	FormatAIFF
+	FormatVorbis
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatMP3:
+		return "MP3"
+	case FormatWAV:
+		return "WAV"
+	case FormatFLAC:
+		return "FLAC"
+	case FormatAIFF:
+		return "AIFF"
+	case FormatVorbis:
+		return "Ogg Vorbis"
 	default:
-		err = fmt.Errorf("Such format is not supported. Only supporting .mp3, .wav and .flac")
+		return "unknown"
+	}
+}
+
+// Formats that can be decoded, for the user to see
+func SupportedFormats() string {
+	return strings.Join([]string{FormatMP3.String(), FormatWAV.String(), FormatFLAC.String(), FormatAIFF.String(), FormatVorbis.String()}, ", ")
+}
+
+// Recognises the format by the first bytes of the file, extension is not taken into account
+func DetectFormat(head []byte) Format {
+	has := func(offset int, magic string) bool {
+		return len(head) >= offset+len(magic) && string(head[offset:offset+len(magic)]) == magic
+	}
+	switch {
+	case has(0, "RIFF") && has(8, "WAVE"):
+		return FormatWAV
+	case has(0, "fLaC"):
+		return FormatFLAC
+	case has(0, "FORM") && (has(8, "AIFF") || has(8, "AIFC")):
+		return FormatAIFF
+	case has(0, "OggS") && has(29, "vorbis"):
+		return FormatVorbis
+	case has(0, "ID3"):
+		return FormatMP3
+	// MPEG audio frame sync
+	case len(head) >= 2 && head[0] == 0xff && head[1]&0xe0 == 0xe0:
+		return FormatMP3
+	}
+	return FormatUnknown
+}
+
+// Bytes needed by DetectFormat
+const headSize = 36
+
+// Format of the content "r" starts with, only the bytes DetectFormat needs are read
+func ReadFormat(r io.Reader) (Format, error) {
+	head := make([]byte, headSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return FormatUnknown, err
 	}
+	return DetectFormat(head[:n]), nil
+}
+
+// Errors caused by the file content are returned as *DecodeError
+func Decode(f *os.File) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, format, _, err := decode(f)
 	return streamer, format, err
 }
 
-// TODO: Pass slice here to avoid reallocations
-func FileToMonosamples(path string) (monoSamples []float32, err error) {
+func decode(f *os.File) (beep.StreamSeekCloser, beep.Format, Format, error) {
+	head := make([]byte, headSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, beep.Format{}, FormatUnknown, err
+	}
+	if n == 0 {
+		return nil, beep.Format{}, FormatUnknown, &DecodeError{Kind: ErrKindTruncated, Err: io.ErrUnexpectedEOF}
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, beep.Format{}, FormatUnknown, err
+	}
+	format := DetectFormat(head[:n])
+	streamer, beepFormat, err := decodeAs(f, format)
+	if err != nil {
+		return nil, beep.Format{}, format, classifyDecodeError(format, err)
+	}
+	if beepFormat.NumChannels < 1 || beepFormat.NumChannels > MaxChannels {
+		streamer.Close()
+		return nil, beep.Format{}, format, &DecodeError{Kind: ErrKindChannels, Format: format, Channels: beepFormat.NumChannels}
+	}
+	// FLAC decoder panics on sample sizes it doesn't know while streaming
+	if format == FormatFLAC && (beepFormat.Precision < 1 || beepFormat.Precision > 3) {
+		streamer.Close()
+		return nil, beep.Format{}, format, &DecodeError{
+			Kind:   ErrKindUnsupportedCodec,
+			Format: format,
+			Err:    fmt.Errorf("%d bit samples", beepFormat.Precision*8),
+		}
+	}
+	// Vorbis decoder reads only the first two channels of every frame, the rest shift into the next frames
+	if format == FormatVorbis && beepFormat.NumChannels > 2 {
+		streamer.Close()
+		return nil, beep.Format{}, format, &DecodeError{Kind: ErrKindChannels, Format: format, Channels: beepFormat.NumChannels}
+	}
+	return streamer, beepFormat, format, nil
+}
+
+func decodeAs(f *os.File, format Format) (beep.StreamSeekCloser, beep.Format, error) {
+	switch format {
+	case FormatMP3:
+		return mp3.Decode(f)
+	case FormatWAV:
+		return wav.Decode(f)
+	case FormatFLAC:
+		return flac.Decode(f)
+	case FormatAIFF:
+		return decodeAIFF(f)
+	case FormatVorbis:
+		return decodeVorbis(f)
+	default:
+		return nil, beep.Format{}, fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Base(f.Name()))
+	}
+}
+
+// How many frames are decoded between progress reports
+const progressChunk = 1 << 16
+
+// Decodes the file at "path" into samples. "onProgress" is called from the decoding goroutine
+// with the already decoded prefix, which is never modified afterwards, and progress in 0..1.
+func DecodeSamples(ctx context.Context, path string, onProgress func(decoded Samples, progress float64)) (samples Samples, err error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return []float32{}, err
+		return Samples{}, err
 	}
 
-	streamer, _, err := Decode(file)
+	streamer, _, format, err := decode(file)
 	if err != nil {
-		return []float32{}, err
+		file.Close()
+		return Samples{}, err
 	}
 	defer streamer.Close()
 
+	total := streamer.Len()
+	samples.Mid = make([]float32, 0, total)
+	samples.Side = make([]float32, 0, total)
 	buf := make([][2]float64, 1024)
-	monoSamples = monoSamples[:0]
+	reported := 0
 	for {
+		if err := ctx.Err(); err != nil {
+			return Samples{}, err
+		}
 		n, ok := streamer.Stream(buf)
 		if !ok {
 			break
@@ -53,8 +184,56 @@ func FileToMonosamples(path string) (monoSamples []float32, err error) {
 		for i := range n {
 			lSample := buf[i][0]
 			rSample := buf[i][1]
-			monoSamples = append(monoSamples, float32((lSample+rSample)*0.5))
+			samples.Mid = append(samples.Mid, float32((lSample+rSample)*0.5))
+			samples.Side = append(samples.Side, float32((lSample-rSample)*0.5))
 		}
+		if samples.Len()-reported >= progressChunk && total > 0 {
+			reported = samples.Len()
+			onProgress(samples, min(float64(reported)/float64(total), 1))
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return Samples{}, classifyStreamError(format, err)
+	}
+	onProgress(samples, 1)
+	return samples, nil
+}
+
+// Decodes up to "frames" leading frames of the file as mid channel, resampled to "sampleRate" if needed
+func DecodeMono(ctx context.Context, path string, sampleRate int, frames int) ([]float32, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, beepFormat, format, err := decode(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	defer decoder.Close()
+
+	var streamer beep.Streamer = decoder
+	if int(beepFormat.SampleRate) != sampleRate {
+		streamer = beep.Resample(4, beepFormat.SampleRate, beep.SampleRate(sampleRate), streamer)
+	}
+	streamer = beep.Take(frames, streamer)
+	mid := make([]float32, 0, frames)
+	buf := make([][2]float64, 1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, ok := streamer.Stream(buf)
+		if !ok {
+			break
+		}
+		for i := range n {
+			mid = append(mid, float32((buf[i][0]+buf[i][1])*0.5))
+		}
+	}
+	if err := decoder.Err(); err != nil {
+		return nil, classifyStreamError(format, err)
 	}
-	return monoSamples, nil
+	return mid, nil
 }
@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 44100 as 80 bit extended
+var rate44100 = [10]byte{0x40, 0x0e, 0xac, 0x44, 0, 0, 0, 0, 0, 0}
+
+func makeAIFF(t *testing.T, compression string, bits int, frames [][2]float64) string {
+	t.Helper()
+	var data bytes.Buffer
+	for _, f := range frames {
+		for _, v := range f {
+			switch compression {
+			case "fl32":
+				binary.Write(&data, binary.BigEndian, float32(v))
+			case "sowt":
+				binary.Write(&data, binary.LittleEndian, int16(v*(1<<15)))
+			default:
+				binary.Write(&data, binary.BigEndian, int16(v*(1<<15)))
+			}
+		}
+	}
+	var comm bytes.Buffer
+	binary.Write(&comm, binary.BigEndian, int16(2))
+	binary.Write(&comm, binary.BigEndian, uint32(len(frames)))
+	binary.Write(&comm, binary.BigEndian, int16(bits))
+	comm.Write(rate44100[:])
+	formType := "AIFF"
+	if compression != "" {
+		formType = "AIFC"
+		comm.WriteString(compression)
+		// Empty pascal string padded to even size
+		comm.Write([]byte{0, 0})
+	}
+
+	var body bytes.Buffer
+	body.WriteString(formType)
+	// Sound data goes first to check that chunk order doesn't matter
+	body.WriteString("SSND")
+	binary.Write(&body, binary.BigEndian, uint32(8+data.Len()))
+	binary.Write(&body, binary.BigEndian, [2]uint32{})
+	body.Write(data.Bytes())
+	body.WriteString("COMM")
+	binary.Write(&body, binary.BigEndian, uint32(comm.Len()))
+	body.Write(comm.Bytes())
+
+	var file bytes.Buffer
+	file.WriteString("FORM")
+	binary.Write(&file, binary.BigEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+	path := filepath.Join(t.TempDir(), "test.bin")
+	if err := os.WriteFile(path, file.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDecodeAIFF(t *testing.T) {
+	frames := [][2]float64{{0, 0}, {0.5, -0.5}, {-0.25, 0.25}, {0.75, -1}}
+	for _, compression := range []string{"", "sowt", "fl32"} {
+		bits := 16
+		if compression == "fl32" {
+			bits = 32
+		}
+		f, err := os.Open(makeAIFF(t, compression, bits, frames))
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamer, format, err := Decode(f)
+		if err != nil {
+			t.Fatalf("%q: %v", compression, err)
+		}
+		if format.SampleRate != 44100 || format.NumChannels != 2 {
+			t.Errorf("%q: got format %+v", compression, format)
+		}
+		if streamer.Len() != len(frames) {
+			t.Errorf("%q: got %d frames, want %d", compression, streamer.Len(), len(frames))
+		}
+		buf := make([][2]float64, 16)
+		n, _ := streamer.Stream(buf)
+		if n != len(frames) {
+			t.Fatalf("%q: streamed %d frames, want %d", compression, n, len(frames))
+		}
+		for i, want := range frames {
+			for ch := range 2 {
+				if math.Abs(buf[i][ch]-want[ch]) > 1e-4 {
+					t.Errorf("%q: frame %d ch %d: got %v, want %v", compression, i, ch, buf[i][ch], want[ch])
+				}
+			}
+		}
+		if err = streamer.Seek(2); err != nil {
+			t.Fatal(err)
+		}
+		if n, _ = streamer.Stream(buf); n != 2 || math.Abs(buf[0][0]+0.25) > 1e-4 {
+			t.Errorf("%q: after seek got %d frames starting with %v", compression, n, buf[0])
+		}
+		streamer.Close()
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	page := func(packet string) []byte {
+		head := make([]byte, 28, 64)
+		copy(head, "OggS")
+		return append(head, packet...)
+	}
+	cases := []struct {
+		head []byte
+		want Format
+	}{
+		{[]byte("RIFF\x00\x00\x00\x00WAVEfmt "), FormatWAV},
+		{[]byte("fLaC\x00\x00\x00\x22"), FormatFLAC},
+		{[]byte("FORM\x00\x00\x00\x00AIFC"), FormatAIFF},
+		{[]byte("ID3\x04\x00"), FormatMP3},
+		{[]byte{0xff, 0xfb, 0x90, 0x64}, FormatMP3},
+		{page("\x01vorbis"), FormatVorbis},
+		{[]byte("plain text"), FormatUnknown},
+	}
+	for _, c := range cases {
+		if got := DetectFormat(c.head); got != c.want {
+			t.Errorf("%q: got %v, want %v", c.head, got, c.want)
+		}
+	}
+}
+
+func writeFile(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDecodeErrorKinds(t *testing.T) {
+	aiff, err := os.ReadFile(makeAIFF(t, "", 16, [][2]float64{{0, 0}, {0.5, 0.5}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noChannels := bytes.Clone(aiff)
+	commAt := bytes.Index(noChannels, []byte("COMM")) + 8
+	binary.BigEndian.PutUint16(noChannels[commAt:], 0)
+	// Sample size is 2 bytes after channels and frames, the rate follows it
+	wideSamples := bytes.Clone(aiff)
+	binary.BigEndian.PutUint16(wideSamples[commAt+6:], 40)
+	halfHz := bytes.Clone(aiff)
+	copy(halfHz[commAt+8:], []byte{0x3f, 0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0})
+	zeroHz := bytes.Clone(aiff)
+	copy(zeroHz[commAt+8:], make([]byte, 10))
+
+	cases := []struct {
+		name    string
+		content []byte
+		want    DecodeErrorKind
+	}{
+		{"unknown", []byte("definitely not audio"), ErrKindUnsupportedCodec},
+		{"empty", nil, ErrKindTruncated},
+		{"truncated aiff", aiff[:len(aiff)-20], ErrKindTruncated},
+		{"wav without format chunk", []byte("RIFF\x00\x00\x00\x00WAVEdata\x00\x00\x00\x00"), ErrKindBadHeader},
+		{"aiff without channels", noChannels, ErrKindChannels},
+		{"aiff with 40 bit samples", wideSamples, ErrKindBadHeader},
+		{"aiff at 0.5 Hz", halfHz, ErrKindBadHeader},
+		{"aiff at 0 Hz", zeroHz, ErrKindBadHeader},
+		{"vorbis without setup", append([]byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), "\x01vorbis\x00\x00\x00\x00\x02"...), ErrKindBadHeader},
+	}
+	for _, c := range cases {
+		f, err := os.Open(writeFile(t, c.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = Decode(f)
+		f.Close()
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("%s: got %v, want DecodeError", c.name, err)
+			continue
+		}
+		if decodeErr.Kind != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, decodeErr.Kind, c.want)
+		}
+	}
+}
+
+func TestDecodeUnsupported(t *testing.T) {
+	f, err := os.Open(writeFile(t, []byte("definitely not audio")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, _, err = Decode(f); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("got %v, want unsupported format", err)
+	}
+}
@@ -0,0 +1,85 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type DecodeErrorKind int
+
+const (
+	ErrKindUnsupportedCodec DecodeErrorKind = iota
+	ErrKindTruncated
+	ErrKindBadHeader
+	ErrKindCorrupted
+	ErrKindChannels
+)
+
+func (k DecodeErrorKind) String() string {
+	switch k {
+	case ErrKindUnsupportedCodec:
+		return "unsupported codec"
+	case ErrKindTruncated:
+		return "truncated file"
+	case ErrKindBadHeader:
+		return "bad header"
+	case ErrKindCorrupted:
+		return "corrupted data"
+	case ErrKindChannels:
+		return "unsupported channel count"
+	default:
+		return "unknown"
+	}
+}
+
+// Surround layouts up to 7.1, anything above is most likely a broken header
+const MaxChannels = 8
+
+// Reason why an audio file can't be decoded, detailed enough to explain it to the user
+type DecodeError struct {
+	Kind     DecodeErrorKind
+	Format   Format // as detected by content
+	Channels int    // for ErrKindChannels
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("decoding %s: %s", e.Format, e.Kind)
+	}
+	return fmt.Sprintf("decoding %s: %s: %v", e.Format, e.Kind, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoders of the third-party modules return untyped errors, so kind is guessed by their messages
+func classifyDecodeError(format Format, err error) *DecodeError {
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return decodeErr
+	}
+	res := &DecodeError{Kind: ErrKindBadHeader, Format: format, Err: err}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		res.Kind = ErrKindTruncated
+	case errors.Is(err, ErrUnsupportedFormat), strings.Contains(msg, "unsupported"), strings.Contains(msg, "not yet implemented"):
+		res.Kind = ErrKindUnsupportedCodec
+	case strings.Contains(msg, "channels"):
+		res.Kind = ErrKindChannels
+	}
+	return res
+}
+
+// Stream errors happen after the header was read fine
+func classifyStreamError(format Format, err error) *DecodeError {
+	kind := ErrKindCorrupted
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		kind = ErrKindTruncated
+	}
+	return &DecodeError{Kind: kind, Format: format, Err: err}
+}
@@ -0,0 +1,31 @@
+package audio
+
+// Decoded samples of the first two channels. They are stored as mid (L+R)/2 and side (L-R)/2,
+// so mono view is still cheap and both channels can be restored without extra memory.
+type Samples struct {
+	Mid  []float32
+	Side []float32
+}
+
+func (s Samples) Len() int {
+	return len(s.Mid)
+}
+
+func (s Samples) IsEmpty() bool {
+	return len(s.Mid) == 0
+}
+
+func (s Samples) Left(idx int) float32 {
+	return s.Mid[idx] + s.Side[idx]
+}
+
+func (s Samples) Right(idx int) float32 {
+	return s.Mid[idx] - s.Side[idx]
+}
+
+func (s Samples) Reset() Samples {
+	return Samples{
+		Mid:  s.Mid[:0],
+		Side: s.Side[:0],
+	}
+}
@@ -0,0 +1,57 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/vorbis"
+)
+
+// Ogg reader indexes by lengths it reads from pages and panics on broken ones, so panics become errors
+func decodeVorbis(f *os.File) (s beep.StreamSeekCloser, format beep.Format, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s, format, err = nil, beep.Format{}, fmt.Errorf("ogg/vorbis: broken page: %v", r)
+		}
+	}()
+	s, format, err = vorbis.Decode(f)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	return &vorbisStreamer{StreamSeekCloser: s}, format, nil
+}
+
+type vorbisStreamer struct {
+	beep.StreamSeekCloser
+	err error
+}
+
+func (v *vorbisStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	if v.err != nil {
+		return 0, false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			v.err = fmt.Errorf("ogg/vorbis: broken page: %v", r)
+			n, ok = 0, false
+		}
+	}()
+	return v.StreamSeekCloser.Stream(samples)
+}
+
+func (v *vorbisStreamer) Err() error {
+	if v.err != nil {
+		return v.err
+	}
+	return v.StreamSeekCloser.Err()
+}
+
+func (v *vorbisStreamer) Seek(p int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("ogg/vorbis: broken page: %v", r)
+		}
+	}()
+	return v.StreamSeekCloser.Seek(p)
+}
@@ -0,0 +1,224 @@
+// Audio and its markers together in a single zip file, which is what gets sent to colleagues
+package bundle
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const (
+	Ext          = ".rpb"
+	Version      = 1
+	manifestName = "manifest.json"
+	markersName  = "markers.rpt"
+	audioDir     = "audio"
+)
+
+var (
+	ErrNoManifest = errors.New("bundle has no manifest")
+	ErrVersion    = errors.New("bundle is made by a newer version")
+	ErrHash       = errors.New("content doesn't match its hash")
+)
+
+// Error of a file in the bundle which is missing, damaged or changed since the bundle was made
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// What is inside the bundle. It's written last, when hashes of everything else are known
+type Manifest struct {
+	Version  int
+	Created  time.Time
+	Audio    []File // the first one is the track markers are made for
+	Markers  File   // .rpt, it also carries the track normalisation
+	Settings Settings
+}
+
+type File struct {
+	Path   string // slash separated path in the bundle
+	Size   int64
+	SHA256 string
+}
+
+// Player state and tempo of the project
+type Settings struct {
+	Volume float64
+	Muted  bool
+	Tempo  float64 `json:",omitempty"` // bpm, 0 if the project has none. The .rpt carries it as well
+}
+
+// Writes audio files and markers as "Manifest" describes them. Audio is stored as it is, it's compressed already
+func Write(w io.Writer, audioPaths []string, markers []byte, settings Settings) error {
+	zw := zip.NewWriter(w)
+	manifest := Manifest{Version: Version, Created: time.Now().UTC(), Settings: settings}
+	for _, it := range audioPaths {
+		file, err := writeAudio(zw, it)
+		if err != nil {
+			return err
+		}
+		manifest.Audio = append(manifest.Audio, file)
+	}
+	var err error
+	manifest.Markers, err = writeEntry(zw, markersName, zip.Deflate, func(w io.Writer) error {
+		_, err := w.Write(markers)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	mw, err := zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	if _, err = mw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeAudio(zw *zip.Writer, filePath string) (File, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	name := path.Join(audioDir, filepath.Base(filePath))
+	return writeEntry(zw, name, zip.Store, func(w io.Writer) error {
+		_, err := io.Copy(w, f)
+		return err
+	})
+}
+
+func writeEntry(zw *zip.Writer, name string, method uint16, write func(io.Writer) error) (File, error) {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: time.Now()})
+	if err != nil {
+		return File{}, err
+	}
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(w, h)}
+	if err = write(cw); err != nil {
+		return File{}, err
+	}
+	return File{Path: name, Size: cw.n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Unpacked bundle. Paths are in the directory it was extracted to
+type Extracted struct {
+	Manifest Manifest
+	Audio    []string
+	Markers  string
+}
+
+// Unpacks everything the manifest lists into "dir", checking sizes and hashes on the way.
+// Files which aren't in the manifest are left out
+func Extract(bundlePath, dir string) (Extracted, error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return Extracted{}, err
+	}
+	defer zr.Close()
+	manifest, err := readManifest(&zr.Reader)
+	if err != nil {
+		return Extracted{}, err
+	}
+	extracted := Extracted{Manifest: manifest}
+	for _, it := range manifest.Audio {
+		dst, err := extractFile(&zr.Reader, it, dir)
+		if err != nil {
+			return Extracted{}, err
+		}
+		extracted.Audio = append(extracted.Audio, dst)
+	}
+	if extracted.Markers, err = extractFile(&zr.Reader, manifest.Markers, dir); err != nil {
+		return Extracted{}, err
+	}
+	return extracted, nil
+}
+
+func readManifest(zr *zip.Reader) (Manifest, error) {
+	var manifest Manifest
+	f, err := zr.Open(manifestName)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, ErrNoManifest
+	}
+	if err != nil {
+		return manifest, err
+	}
+	defer f.Close()
+	if err = json.NewDecoder(f).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %w", manifestName, err)
+	}
+	if manifest.Version > Version {
+		return manifest, ErrVersion
+	}
+	if len(manifest.Audio) == 0 {
+		return manifest, FileError{Path: audioDir, Err: os.ErrNotExist}
+	}
+	return manifest, nil
+}
+
+func extractFile(zr *zip.Reader, file File, dir string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+		return "", FileError{Path: file.Path, Err: errors.New("path leads out of the bundle")}
+	}
+	src, err := zr.Open(file.Path)
+	if err != nil {
+		return "", FileError{Path: file.Path, Err: err}
+	}
+	defer src.Close()
+	dst := filepath.Join(dir, filepath.FromSlash(file.Path))
+	if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	// One byte over the size is enough to tell the file is bigger
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(src, file.Size+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", FileError{Path: file.Path, Err: err}
+	}
+	if n != file.Size || hex.EncodeToString(h.Sum(nil)) != file.SHA256 {
+		os.Remove(dst)
+		return "", FileError{Path: file.Path, Err: ErrHash}
+	}
+	return dst, nil
+}
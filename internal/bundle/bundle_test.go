@@ -0,0 +1,144 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestBundle(t *testing.T, audio []byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "track.mp3")
+	if err := os.WriteFile(audioPath, audio, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, []string{audioPath}, []byte(`{"Version":1}`), Settings{Volume: 0.7, Tempo: 92.5}); err != nil {
+		t.Fatal(err)
+	}
+	bundlePath := filepath.Join(dir, "project"+Ext)
+	if err := os.WriteFile(bundlePath, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return bundlePath
+}
+
+// Copies the bundle, letting "edit" change the content of every entry
+func rewriteBundle(t *testing.T, src string, edit func(name string, data []byte) []byte) string {
+	t.Helper()
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, it := range zr.File {
+		rc, err := it.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = edit(it.Name, data)
+		if data == nil {
+			continue
+		}
+		w, err := zw.Create(it.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	zw.Close()
+	dst := filepath.Join(t.TempDir(), "edited"+Ext)
+	if err = os.WriteFile(dst, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	audio := bytes.Repeat([]byte("ID3 audio frames "), 1000)
+	bundlePath := writeTestBundle(t, audio)
+	dir := t.TempDir()
+	got, err := Extract(bundlePath, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Audio) != 1 || filepath.Base(got.Audio[0]) != "track.mp3" {
+		t.Fatalf("audio: got %v", got.Audio)
+	}
+	data, err := os.ReadFile(got.Audio[0])
+	if err != nil || !bytes.Equal(data, audio) {
+		t.Errorf("audio content differs, err %v", err)
+	}
+	markers, err := os.ReadFile(got.Markers)
+	if err != nil || string(markers) != `{"Version":1}` {
+		t.Errorf("markers: got %q, err %v", markers, err)
+	}
+	settings := got.Manifest.Settings
+	if settings.Volume != 0.7 || settings.Tempo != 92.5 || got.Manifest.Audio[0].Size != int64(len(audio)) {
+		t.Errorf("manifest: got %+v", got.Manifest)
+	}
+}
+
+func TestExtractRejectsChangedContent(t *testing.T) {
+	bundlePath := rewriteBundle(t, writeTestBundle(t, []byte("original audio")), func(name string, data []byte) []byte {
+		if name == "audio/track.mp3" {
+			return []byte("replaced audio")
+		}
+		return data
+	})
+	_, err := Extract(bundlePath, t.TempDir())
+	var fileErr FileError
+	if !errors.Is(err, ErrHash) || !errors.As(err, &fileErr) || fileErr.Path != "audio/track.mp3" {
+		t.Errorf("got %v, want hash error of the audio", err)
+	}
+}
+
+func TestExtractRejectsMissingParts(t *testing.T) {
+	original := writeTestBundle(t, []byte("audio"))
+	noManifest := rewriteBundle(t, original, func(name string, data []byte) []byte {
+		if name == manifestName {
+			return nil
+		}
+		return data
+	})
+	if _, err := Extract(noManifest, t.TempDir()); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("without manifest: got %v", err)
+	}
+	noMarkers := rewriteBundle(t, original, func(name string, data []byte) []byte {
+		if name == markersName {
+			return nil
+		}
+		return data
+	})
+	if _, err := Extract(noMarkers, t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("without markers: got %v", err)
+	}
+}
+
+func TestExtractStaysInDir(t *testing.T) {
+	bundlePath := rewriteBundle(t, writeTestBundle(t, []byte("audio")), func(name string, data []byte) []byte {
+		if name == manifestName {
+			return bytes.Replace(data, []byte(`"audio/track.mp3"`), []byte(`"../track.mp3"`), 1)
+		}
+		return data
+	})
+	dir := t.TempDir()
+	if _, err := Extract(bundlePath, filepath.Join(dir, "inner")); err == nil {
+		t.Error("extracted a path leading out of the directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "track.mp3")); err == nil {
+		t.Error("file was written out of the directory")
+	}
+}
@@ -136,6 +136,12 @@ func FormatSeconds(seconds float64) string {
 	return fmt.Sprintf("%d:%02d:%02d", int(hours), int(math.Mod(minutes, 60)), int(math.Mod(seconds, 60)))
 }
 
+// Same as FormatSeconds, but with milliseconds
+func FormatSecondsPrecise(seconds float64) string {
+	whole := math.Floor(seconds)
+	return fmt.Sprintf("%s.%03d", FormatSeconds(whole), int((seconds-whole)*1000))
+}
+
 func ParseSeconds(secondsStr string) (float64, error) {
 	if secondsStr == "" || !strings.Contains(secondsStr, ":") {
 		return 0, fmt.Errorf("Given incorrect string to parse from seconds")
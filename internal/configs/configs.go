@@ -39,8 +39,12 @@ func Load() (*Configs, error) {
 }
 
 type Configs struct {
-	Lang            string    `json:"lang"`
-	LastUpdateCheck time.Time `json:"last_update_check"`
+	Lang             string    `json:"lang"`
+	LastUpdateCheck  time.Time `json:"last_update_check"`
+	OutputSampleRate int       `json:"output_sample_rate,omitempty"`
+	ResampleQuality  int       `json:"resample_quality,omitempty"`
+	BufferMs         int       `json:"buffer_ms,omitempty"`
+	PeakCacheMB      int       `json:"peak_cache_mb,omitempty"`
 }
 
 func (c *Configs) Save() error {
@@ -71,3 +75,45 @@ func (c *Configs) GetLocale() (string, error) {
 func (c *Configs) MarkUpdateChecked() {
 	c.LastUpdateCheck = time.Now().UTC()
 }
+
+var (
+	OutputSampleRates = []int{44100, 48000, 96000}
+	ResampleQualities = []int{1, 4, 8} // fast, standard, high
+	BufferSizesMs     = []int{20, 50, 100, 200}
+)
+
+const (
+	defaultOutputSampleRate = 48000
+	defaultResampleQuality  = 4
+	defaultBufferMs         = 100
+	defaultPeakCacheMB      = 256
+)
+
+func (c *Configs) GetOutputSampleRate() int {
+	if c.OutputSampleRate == 0 {
+		return defaultOutputSampleRate
+	}
+	return c.OutputSampleRate
+}
+
+func (c *Configs) GetResampleQuality() int {
+	if c.ResampleQuality == 0 {
+		return defaultResampleQuality
+	}
+	return c.ResampleQuality
+}
+
+func (c *Configs) GetBufferMs() int {
+	if c.BufferMs == 0 {
+		return defaultBufferMs
+	}
+	return c.BufferMs
+}
+
+// Size limit of the on-disk waveform cache
+func (c *Configs) GetPeakCacheMB() int {
+	if c.PeakCacheMB == 0 {
+		return defaultPeakCacheMB
+	}
+	return c.PeakCacheMB
+}
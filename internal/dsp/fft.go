@@ -0,0 +1,71 @@
+package dsp
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// In-place iterative radix-2 FFT. Size of the buffer must be a power of 2.
+type FFT struct {
+	size     int
+	twiddles []complex128
+	rev      []int
+}
+
+func NewFFT(size int) *FFT {
+	if size&(size-1) != 0 {
+		panic("fft: size must be a power of 2")
+	}
+	f := &FFT{
+		size:     size,
+		twiddles: make([]complex128, size/2),
+		rev:      make([]int, size),
+	}
+	for i := range f.twiddles {
+		f.twiddles[i] = cmplx.Exp(complex(0, -2*math.Pi*float64(i)/float64(size)))
+	}
+	shift := 64 - bits.Len(uint(size-1))
+	for i := range f.rev {
+		f.rev[i] = int(bits.Reverse64(uint64(i)) >> shift)
+	}
+	return f
+}
+
+func (f *FFT) Transform(buf []complex128) {
+	for i, j := range f.rev {
+		if i < j {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+	}
+	for half := 1; half < f.size; half *= 2 {
+		step := f.size / (half * 2)
+		for start := 0; start < f.size; start += half * 2 {
+			for k := range half {
+				t := f.twiddles[k*step] * buf[start+k+half]
+				buf[start+k+half] = buf[start+k] - t
+				buf[start+k] += t
+			}
+		}
+	}
+}
+
+// Inverse transform, scaled so that Inverse(Transform(x)) == x
+func (f *FFT) Inverse(buf []complex128) {
+	for i, it := range buf {
+		buf[i] = cmplx.Conj(it)
+	}
+	f.Transform(buf)
+	scale := 1 / float64(f.size)
+	for i, it := range buf {
+		buf[i] = complex(real(it)*scale, -imag(it)*scale)
+	}
+}
+
+// Smallest power of 2 which is not less than "n"
+func NextPow2(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,54 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestTonePeaksInItsBin(t *testing.T) {
+	const size, bin = 64, 5
+	f := NewFFT(size)
+	buf := make([]complex128, size)
+	for i := range buf {
+		buf[i] = complex(math.Cos(2*math.Pi*bin*float64(i)/size), 0)
+	}
+	f.Transform(buf)
+	// Real cosine splits between the bin and its mirror, half of the size each
+	for i, it := range buf {
+		want := 0.0
+		if i == bin || i == size-bin {
+			want = size / 2
+		}
+		if got := cmplx.Abs(it); math.Abs(got-want) > 1e-9 {
+			t.Errorf("bin %d: got magnitude %f, want %f", i, got, want)
+		}
+	}
+}
+
+func TestInverseRecoversInput(t *testing.T) {
+	const size = 256
+	f := NewFFT(size)
+	rnd := rand.New(rand.NewSource(1))
+	input := make([]complex128, size)
+	for i := range input {
+		input[i] = complex(rnd.Float64()*2-1, rnd.Float64()*2-1)
+	}
+	buf := append([]complex128{}, input...)
+	f.Transform(buf)
+	f.Inverse(buf)
+	for i := range buf {
+		if cmplx.Abs(buf[i]-input[i]) > 1e-12 {
+			t.Fatalf("sample %d: got %v, want %v", i, buf[i], input[i])
+		}
+	}
+}
+
+func TestNextPow2(t *testing.T) {
+	for n, want := range map[int]int{0: 1, 1: 1, 2: 2, 3: 4, 1000: 1024, 1024: 1024, 1025: 2048} {
+		if got := NextPow2(n); got != want {
+			t.Errorf("NextPow2(%d): got %d, want %d", n, got, want)
+		}
+	}
+}
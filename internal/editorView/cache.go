@@ -1,38 +1,189 @@
 package editorview
 
+import (
+	"slices"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/peakcache"
+)
+
 func newCache() cache {
 	return cache{
-		peakMap: make(map[int][][2]float32),
-		levels:  make([]int, maxScrollLvl+1),
-		workers: make([]*cacheWorker, maxScrollLvl+1),
+		peakMap: make(map[int]*peaks),
+		rmsMap:  make(map[int]*rms),
+	}
+}
+
+type waveChannel int
+
+const (
+	chMid waveChannel = iota
+	chSide
+	chLeft
+	chRight
+	channelsAmount
+)
+
+// Min and max pairs for every displayable channel
+type peaks [channelsAmount][][2]float32
+
+// Bounds are clamped, since levels are still growing while samples are being decoded
+func (p *peaks) slice(from, to int) peaks {
+	var res peaks
+	for ch := range p {
+		to := min(to, len(p[ch]))
+		res[ch] = p[ch][min(from, to):to]
+	}
+	return res
+}
+
+// RMS values for every displayable channel
+type rms [channelsAmount][]float32
+
+func (r *rms) slice(from, to int) rms {
+	var res rms
+	for ch := range r {
+		to := min(to, len(r[ch]))
+		res[ch] = r[ch][min(from, to):to]
+	}
+	return res
+}
+
+// Bounds of the peak pyramid: finest bins are as small as possible while their amount stays limited,
+// coarser levels double the bin size until there are just a few bins left
+const (
+	minBinShift     = 4
+	maxFinestBins   = 1 << 18
+	minCoarsestBins = 256
+)
+
+// Power of two bin sizes for a track of "total" samples, from the coarsest to the finest
+func pyramidLevels(total int) []int {
+	shift := minBinShift
+	for total>>shift > maxFinestBins {
+		shift++
+	}
+	levels := []int{1 << shift}
+	for spp := levels[0]; total/spp > minCoarsestBins; {
+		spp *= 2
+		levels = append(levels, spp)
 	}
+	slices.Reverse(levels)
+	return levels
 }
 
-// Stores peak map where "samplesPerPx" is key (level)
+// Stores peak map where samples per bin is key (level). Levels don't depend on the window size,
+// they are built once per file and the view picks the nearest one.
 type cache struct {
-	peakMap     map[int][][2]float32
-	curSlice    [][2]float32
-	workers     []*cacheWorker
+	peakMap     map[int]*peaks
+	rmsMap      map[int]*rms
+	curSlice    peaks
+	curRMS      rms
 	isPopulated bool
-	levels      []int // Stores possible "samplesPerPx" values
+	isDirty     bool  // levels got new data since "curSlice" was taken
+	isStored    bool  // levels came from the on-disk cache
+	isComplete  bool  // whole track is in the levels, including the trailing partial bin
+	scanned     int   // samples already fed into the finest level
+	levels      []int // samples per bin, from the coarsest to the finest
 	curLvl      int
 	leftB       int
 }
 
-// Used to build one level (samplesPerPx) of cache
-type cacheWorker struct {
-	min          float32
-	max          float32
-	samplesPerPx int
-	count        int
-	sliceIdx     int
+// The coarsest level which still has at least one bin per px for "spp" samples per px
+func (c cache) getLevel(spp float32) int {
+	for _, it := range c.levels {
+		if float32(it) <= spp {
+			return it
+		}
+	}
+	return c.levels[len(c.levels)-1]
 }
 
-func (c cache) getLevel(spp float32) int {
-	for i := len(c.levels) - 1; i >= 0; i-- {
-		if float32(c.levels[i]) >= spp {
-			return c.levels[i]
+func (c *cache) reset(total int) {
+	c.levels = pyramidLevels(total)
+	for _, spp := range c.levels {
+		lvl, ok := c.peakMap[spp]
+		if !ok {
+			lvl = &peaks{}
+			c.peakMap[spp] = lvl
+		}
+		rmsLvl, ok := c.rmsMap[spp]
+		if !ok {
+			rmsLvl = &rms{}
+			c.rmsMap[spp] = rmsLvl
+		}
+		for ch := range channelsAmount {
+			lvl[ch] = lvl[ch][:0]
+			rmsLvl[ch] = rmsLvl[ch][:0]
 		}
 	}
-	return c.levels[0]
+	c.scanned = 0
+	c.isComplete = false
+	c.isStored = false
+	c.isPopulated = true
+	c.isDirty = true
+}
+
+// Scans samples up to "to" into the finest level and updates coarser ones,
+// "final" means there are no more samples, so partial bins at the end are added as well
+func (c *cache) extend(samples audio.Samples, to int, final bool) {
+	finest := c.levels[len(c.levels)-1]
+	if !final {
+		to -= to % finest
+	}
+	if to > c.scanned {
+		scanBins(c.peakMap[finest], c.rmsMap[finest], samples, c.scanned, to, finest)
+		c.scanned = to
+	} else if !final || c.isComplete {
+		return
+	}
+	for i := len(c.levels) - 2; i >= 0; i-- {
+		fine, coarse := c.levels[i+1], c.levels[i]
+		reduceLevel(c.peakMap[fine], c.rmsMap[fine], c.peakMap[coarse], c.rmsMap[coarse], final)
+	}
+	c.isComplete = final
+	c.isDirty = true
+}
+
+// Copy of the levels to be written to disk, levels themselves are reused by the next file
+func (ed *Editor) exportPeaks() *peakcache.Peaks {
+	res := &peakcache.Peaks{Levels: make([]peakcache.Level, 0, len(ed.cache.levels))}
+	for _, spp := range ed.cache.levels {
+		lvl := peakcache.Level{
+			SamplesPerBin: spp,
+			Peaks:         make([][][2]float32, channelsAmount),
+			RMS:           make([][]float32, channelsAmount),
+		}
+		for ch := range channelsAmount {
+			lvl.Peaks[ch] = slices.Clone(ed.cache.peakMap[spp][ch])
+			lvl.RMS[ch] = slices.Clone(ed.cache.rmsMap[spp][ch])
+		}
+		res.Levels = append(res.Levels, lvl)
+	}
+	return res
+}
+
+func (ed *Editor) importPeaks(stored *peakcache.Peaks) {
+	ed.cache.levels = ed.cache.levels[:0]
+	ed.cache.isPopulated = false
+	for _, it := range stored.Levels {
+		if len(it.Peaks) != int(channelsAmount) || len(it.RMS) != int(channelsAmount) {
+			continue
+		}
+		lvl, rmsLvl := &peaks{}, &rms{}
+		for ch := range channelsAmount {
+			lvl[ch] = it.Peaks[ch]
+			rmsLvl[ch] = it.RMS[ch]
+		}
+		ed.cache.peakMap[it.SamplesPerBin] = lvl
+		ed.cache.rmsMap[it.SamplesPerBin] = rmsLvl
+		ed.cache.levels = append(ed.cache.levels, it.SamplesPerBin)
+	}
+	if len(ed.cache.levels) == 0 {
+		return
+	}
+	ed.cache.isPopulated = true
+	ed.cache.isStored = true
+	ed.cache.isComplete = true
+	ed.cache.isDirty = true
 }
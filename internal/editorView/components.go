@@ -21,6 +21,7 @@ import (
 	"github.com/spyhere/re-peat/internal/audio"
 	"github.com/spyhere/re-peat/internal/common"
 	micons "github.com/spyhere/re-peat/internal/mIcons"
+	"github.com/spyhere/re-peat/internal/player"
 	tm "github.com/spyhere/re-peat/internal/timeMarkers"
 	"github.com/spyhere/re-peat/internal/ui/theme"
 )
@@ -69,8 +70,35 @@ func mCreateButtonComp(gtx layout.Context, th *theme.RepeatTheme, tag event.Tag,
 	common.RegisterTag(gtx, tag, labelArea)
 }
 
+// Empty "envelope" means RMS is not drawn
+func soundWavesComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32, waves peaks, envelope rms, view waveView, s scroll, c cache) {
+	switch view {
+	case viewStereo:
+		stackedWavesComp(gtx, th, yCenter, [2]waveChannel{chLeft, chRight}, waves, envelope, s, c)
+	case viewMidSide:
+		stackedWavesComp(gtx, th, yCenter, [2]waveChannel{chMid, chSide}, waves, envelope, s, c)
+	default:
+		channelWaveComp(gtx, th, yCenter, waves[chMid], envelope[chMid], s, c)
+	}
+}
+
+// Draws 2 channels on top of each other, each one taking half of the height
+func stackedWavesComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32, chs [2]waveChannel, waves peaks, envelope rms, s scroll, c cache) {
+	half := yCenter / 2
+	top, bottom := chs[0], chs[1]
+	channelWaveComp(gtx, th, half, waves[top], envelope[top], s, c)
+	y := int(common.Snap(yCenter))
+	common.DrawBox(gtx, common.Box{
+		Size:  image.Rect(0, y, gtx.Constraints.Max.X+waveEdgePadding, y+th.Sizing.Editor.ChannelSepW),
+		Color: th.Palette.Editor.ChannelSep,
+	})
+	offsetBy(gtx, image.Pt(0, y), func() {
+		channelWaveComp(gtx, th, half, waves[bottom], envelope[bottom], s, c)
+	})
+}
+
 // TODO: Improve visuals (make less bulky)
-func soundWavesComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32, waves [][2]float32, s scroll, c cache) {
+func channelWaveComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32, waves [][2]float32, envelope []float32, s scroll, c cache) {
 	if len(waves) == 0 {
 		return
 	}
@@ -90,6 +118,9 @@ func soundWavesComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32,
 		sample1 := s.leftB + int(float32(px+1)*s.samplesPerPx)
 		i0 := (sample0 / c.curLvl) - c.leftB
 		i1 := (sample1 / c.curLvl) - c.leftB
+		if i0 >= len(waves) {
+			break
+		}
 		minV := min(i0+1, len(waves))
 		i1 = common.Clamp(minV, i1, len(waves))
 		if i0 == lastI0 && i1 == lastI1 {
@@ -121,6 +152,9 @@ func soundWavesComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32,
 		sample1 := s.leftB + int(float32(px+1)*s.samplesPerPx)
 		i0 := (sample0 / c.curLvl) - c.leftB
 		i1 := (sample1 / c.curLvl) - c.leftB
+		if i0 >= len(waves) {
+			continue
+		}
 		minV := min(i0+1, len(waves))
 		i1 = common.Clamp(minV, i1, len(waves))
 		if i0 == lastI0 && i1 == lastI1 {
@@ -147,6 +181,91 @@ func soundWavesComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32,
 	paint.FillShape(gtx.Ops, th.Palette.Editor.SoundWave,
 		clip.Outline{Path: path.End()}.Op(),
 	)
+	rmsEnvelopeComp(gtx, th, yCenter, envelope, s, c)
+}
+
+// Symmetric RMS shape drawn over the peaks
+func rmsEnvelopeComp(gtx layout.Context, th *theme.RepeatTheme, yCenter float32, envelope []float32, s scroll, c cache) {
+	if len(envelope) == 0 {
+		return
+	}
+	width := gtx.Constraints.Max.X + waveEdgePadding
+	levels := make([]float32, 0, width)
+	lastI0, lastI1 := -1, -1
+	var level float32
+	for px := range width {
+		sample0 := s.leftB + int(float32(px)*s.samplesPerPx)
+		sample1 := s.leftB + int(float32(px+1)*s.samplesPerPx)
+		i0 := (sample0 / c.curLvl) - c.leftB
+		i1 := (sample1 / c.curLvl) - c.leftB
+		if i0 >= len(envelope) {
+			break
+		}
+		minV := min(i0+1, len(envelope))
+		i1 = common.Clamp(minV, i1, len(envelope))
+		if i0 != lastI0 || i1 != lastI1 {
+			lastI0, lastI1 = i0, i1
+			level = reduceRMS(envelope[i0:i1])
+		}
+		levels = append(levels, level)
+	}
+
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	for px, it := range levels {
+		pt := f32.Pt(float32(px), common.Snap(yCenter-it*yCenter))
+		if px == 0 {
+			path.MoveTo(pt)
+		} else {
+			path.LineTo(pt)
+		}
+	}
+	for px := len(levels) - 1; px >= 0; px-- {
+		path.LineTo(f32.Pt(float32(px), common.Snap(yCenter+levels[px]*yCenter)))
+	}
+	path.Close()
+	paint.FillShape(gtx.Ops, th.Palette.Editor.RMS,
+		clip.Outline{Path: path.End()}.Op(),
+	)
+}
+
+// dB range mapped onto the wave area height
+const (
+	envelopeTopDB    = 12.0
+	envelopeBottomDB = -48.0
+)
+
+// Line of the resulting playback gain, "height" is the wave area height
+func volumeEnvelopeComp(gtx layout.Context, th *theme.RepeatTheme, env player.Envelope, height int, s scroll) {
+	if env.IsFlat() {
+		return
+	}
+	width := gtx.Constraints.Max.X + waveEdgePadding
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	for px := range width {
+		db := common.Clamp(envelopeBottomDB, env.At(s.leftB+int(float32(px)*s.samplesPerPx)), envelopeTopDB)
+		y := float32((envelopeTopDB - db) / (envelopeTopDB - envelopeBottomDB) * float64(height))
+		if px == 0 {
+			path.MoveTo(f32.Pt(0, y))
+		} else {
+			path.LineTo(f32.Pt(float32(px), y))
+		}
+	}
+	stroke := clip.Stroke{
+		Path:  path.End(),
+		Width: float32(th.Sizing.Editor.EnvelopeW),
+	}.Op().Push(gtx.Ops)
+	paint.ColorOp{Color: th.Palette.Editor.Envelope}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	stroke.Pop()
+}
+
+func spectrogramComp(gtx layout.Context, imgOp paint.ImageOp) {
+	size := imgOp.Size()
+	defer clip.Rect(image.Rectangle{Max: size}).Push(gtx.Ops).Pop()
+	imgOp.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
 }
 
 var timeIntervals = [5]float32{1, 5, 10, 30, 60}
@@ -409,3 +528,119 @@ func drawCreateCacheButton(gtx layout.Context, th *theme.RepeatTheme, cl *widget
 		return btn.Layout(gtx)
 	})
 }
+
+// Progress text centered in the top margin of the editor
+func decodingProgressComp(gtx layout.Context, th *theme.RepeatTheme, waveM int, txt string) {
+	txtM, txtDims := common.MakeMacro(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Point{}
+		return material.Body2(th.Theme, txt).Layout(gtx)
+	})
+	pos := image.Pt(gtx.Constraints.Max.X/2-txtDims.Size.X/2, waveM/2-txtDims.Size.Y/2)
+	common.OffsetBy(gtx, pos, func(gtx layout.Context) {
+		txtM.Add(gtx.Ops)
+	})
+}
+
+type toolbarItem struct {
+	cl   *widget.Clickable
+	text string
+}
+
+// Row of clickable chips placed in the bottom margin of the editor
+func toolbarComp(gtx layout.Context, th *theme.RepeatTheme, waveM int, items ...toolbarItem) {
+	gap := th.Sizing.Editor.Toolbar.Gap
+	x := th.Sizing.Editor.Toolbar.MargL
+	for _, it := range items {
+		chipM, chipDims := common.MakeMacro(gtx, func(gtx layout.Context) layout.Dimensions {
+			return common.DrawChip(gtx, th, common.ChipProps{
+				Text:     it.text,
+				Selected: true,
+				HideIcon: true,
+				Cl:       it.cl,
+			})
+		})
+		y := gtx.Constraints.Max.Y - waveM/2 - chipDims.Size.Y/2
+		common.OffsetBy(gtx, image.Pt(x, y), func(gtx layout.Context) {
+			chipM.Add(gtx.Ops)
+		})
+		x += chipDims.Size.X + gap
+	}
+}
+
+type overviewProps struct {
+	waves        [][2]float32
+	scroll       scroll
+	playhead     int
+	markers      tm.TimeMarkers
+	totalSamples int
+	tag          event.Tag
+}
+
+func overviewComp(gtx layout.Context, th *theme.RepeatTheme, props overviewProps) {
+	if len(props.waves) == 0 || props.totalSamples == 0 {
+		return
+	}
+	p := th.Palette.Editor.Overview
+	w, h := gtx.Constraints.Max.X, gtx.Constraints.Max.Y
+	area := image.Rect(0, 0, w, h)
+	common.DrawBox(gtx, common.Box{
+		Size:  area,
+		Color: p.Bg,
+	})
+	toX := func(samples int) int {
+		return int(int64(samples) * int64(w) / int64(props.totalSamples))
+	}
+
+	// Waves
+	yCenter := float32(h) / 2
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	peaksPerPx := float32(len(props.waves)) / float32(w)
+	for px := range w {
+		i0 := int(float32(px) * peaksPerPx)
+		i1 := common.Clamp(min(i0+1, len(props.waves)), int(float32(px+1)*peaksPerPx), len(props.waves))
+		_, high := reducePeaks(props.waves[i0:i1])
+		pt := f32.Pt(float32(px), yCenter-high*yCenter)
+		if px == 0 {
+			path.MoveTo(pt)
+		} else {
+			path.LineTo(pt)
+		}
+	}
+	for px := w - 1; px >= 0; px-- {
+		i0 := int(float32(px) * peaksPerPx)
+		i1 := common.Clamp(min(i0+1, len(props.waves)), int(float32(px+1)*peaksPerPx), len(props.waves))
+		low, _ := reducePeaks(props.waves[i0:i1])
+		path.LineTo(f32.Pt(float32(px), yCenter-low*yCenter+1))
+	}
+	path.Close()
+	paint.FillShape(gtx.Ops, p.Wave, clip.Outline{Path: path.End()}.Op())
+
+	// Markers
+	tickW := th.Sizing.Editor.Overview.TickW
+	for _, m := range props.markers {
+		x := toX(m.Samples)
+		common.DrawBox(gtx, common.Box{
+			Size:  image.Rect(x, 0, x+tickW, h),
+			Color: p.Marker,
+		})
+	}
+
+	// Visible range
+	leftX, rightX := toX(props.scroll.leftB), toX(props.scroll.rightB)
+	rightX = max(rightX, leftX+tickW)
+	common.DrawBox(gtx, common.Box{
+		Size:    image.Rect(leftX, 0, rightX, h),
+		Color:   p.Window,
+		StrokeC: p.WindowStroke,
+		StrokeW: unit.Dp(th.Sizing.Editor.Overview.WindowStrokeW),
+	})
+
+	// Playhead
+	x := toX(props.playhead)
+	common.DrawBox(gtx, common.Box{
+		Size:  image.Rect(x, 0, x+tickW, h),
+		Color: th.Palette.Editor.Playhead,
+	})
+	common.RegisterTag(gtx, props.tag, area)
+}
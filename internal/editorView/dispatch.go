@@ -8,7 +8,7 @@ import (
 )
 
 func (ed *Editor) dispatch(gtx layout.Context) {
-	if len(ed.MonoSamples) == 0 {
+	if !ed.hasWaves() {
 		return
 	}
 	ed.dispatchMEditorEvent(gtx)
@@ -17,6 +17,7 @@ func (ed *Editor) dispatch(gtx layout.Context) {
 	ed.dispatchMLifeEvent(gtx)
 	ed.dispatchSoundWaveEvent(gtx)
 	ed.dispatchNoneEvent(gtx)
+	ed.dispatchOverviewEvent(gtx)
 
 	ed.dispatchMCreateButtonEvent(gtx)
 	ed.dispatchMarkerEvent(gtx)
@@ -38,6 +39,21 @@ func (ed *Editor) dispatchKeyEvents(gtx layout.Context) {
 		key.Filter{
 			Name: key.NameRightArrow,
 		},
+		key.Filter{
+			Name: "F",
+		},
+		key.Filter{
+			Name: "V",
+		},
+		key.Filter{
+			Name: "R",
+		},
+		key.Filter{
+			Name: "S",
+		},
+		key.Filter{
+			Name: "C",
+		},
 	)
 }
 
@@ -89,6 +105,22 @@ func (ed *Editor) dispatchNoneEvent(gtx layout.Context) {
 	)
 }
 
+func (ed *Editor) dispatchOverviewEvent(gtx layout.Context) {
+	common.HandlePointerEvents(
+		gtx,
+		&ed.tags.overview,
+		pointer.Enter|pointer.Press|pointer.Move|pointer.Drag|pointer.Release,
+		func(e pointer.Event) {
+			ed.handlePointer(pointerEvent{
+				Event: e,
+				Target: hitTarget{
+					Kind: hitOverview,
+				},
+			})
+		},
+	)
+}
+
 func (ed *Editor) dispatchMarkerEvent(gtx layout.Context) {
 	for _, marker := range *ed.markers.arr {
 		common.HandlePointerEvents(
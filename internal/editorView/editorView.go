@@ -9,6 +9,7 @@ import (
 	"gioui.org/f32"
 	"gioui.org/io/pointer"
 	"gioui.org/widget"
+	"github.com/spyhere/re-peat/internal/audio"
 	"github.com/spyhere/re-peat/internal/common"
 	"github.com/spyhere/re-peat/internal/state"
 	tm "github.com/spyhere/re-peat/internal/timeMarkers"
@@ -34,6 +35,8 @@ func NewEditor(props EditorProps) Editor {
 		markers:       newMarkers(&props.State.TimeMarkers),
 		mEditor:       newMEditor(),
 		scroll:        newScroll(),
+		spectro:       newSpectro(),
+		showRMS:       true,
 		tags:          newTags(),
 		onStartEditCb: props.OnStartEditCb,
 		onStopEditCb:  props.OnStopEditCb,
@@ -53,8 +56,21 @@ const (
 	modeMEditIntent
 	modeMEdit
 	modeMDrag
+	modeOverview
 )
 
+type waveView int
+
+const (
+	viewMono waveView = iota
+	viewStereo
+	viewMidSide
+)
+
+func (w waveView) next() waveView {
+	return (w + 1) % (viewMidSide + 1)
+}
+
 // TODO: Remove redundant pointers
 type Editor struct {
 	cachedFile    string
@@ -68,24 +84,35 @@ type Editor struct {
 	tags          *tags
 	size          image.Point
 	scroll        scroll
+	overview      overview
 	makeCacheCl   widget.Clickable
 	disabledCl    widget.Clickable
+	followCl      widget.Clickable
+	waveView      waveView
+	waveViewCl    widget.Clickable
+	showRMS       bool
+	rmsCl         widget.Clickable
+	spectro       spectro
+	spectroCl     widget.Clickable
+	contrastCl    widget.Clickable
+	wasPlaying    bool
 	onStartEditCb func()
 	onStopEditCb  func()
 	*state.AppState
 }
 
-func (ed *Editor) getRenderableWaves() [][2]float32 {
+func (ed *Editor) getRenderableWaves() peaks {
 	if !ed.cache.isPopulated {
-		return [][2]float32{}
+		return peaks{}
 	}
 	samplesPerPx := ed.scroll.samplesPerPx
 	visibleSamples := int(samplesPerPx * float32(ed.size.X))
 	leftB := common.Clamp(0, ed.scroll.leftB, ed.AudioMeta.MonoSamplesLen-visibleSamples)
 	rightB := leftB + visibleSamples
-	if leftB == ed.scroll.leftB && rightB == ed.scroll.rightB {
+	if !ed.cache.isDirty && leftB == ed.scroll.leftB && rightB == ed.scroll.rightB {
 		return ed.cache.curSlice
 	}
+	ed.cache.isDirty = false
 	ed.scroll.leftB = leftB
 	ed.scroll.rightB = rightB
 
@@ -93,7 +120,8 @@ func (ed *Editor) getRenderableWaves() [][2]float32 {
 	cacheLeftB := leftB / cacheSPP
 	cacheRightB := rightB / cacheSPP
 
-	ed.cache.curSlice = ed.cache.peakMap[cacheSPP][cacheLeftB:cacheRightB]
+	ed.cache.curSlice = ed.cache.peakMap[cacheSPP].slice(cacheLeftB, cacheRightB)
+	ed.cache.curRMS = ed.cache.rmsMap[cacheSPP].slice(cacheLeftB, cacheRightB)
 	ed.cache.curLvl = cacheSPP
 	ed.cache.leftB = cacheLeftB
 	return ed.cache.curSlice
@@ -105,40 +133,74 @@ func (ed *Editor) SetSize(size image.Point) {
 	ed.size = size
 	if !prev.Eq(ed.size) {
 		ed.waveM = common.PrcToPx(size.Y, ed.Th.Sizing.Editor.WaveM)
-		ed.cache.isPopulated = false
+		if ed.cache.isPopulated {
+			ed.setScrollLimits()
+		}
 	}
 }
 
-// TODO: optimization - parallelise samples scan (~60 ms on resize for now)
-func (ed *Editor) MakePeakMap() {
-	isNewFile := ed.cachedFile != ed.LoadedAFile
-	if len(ed.MonoSamples) == 0 || !ed.HasAudioLoaded() || (!isNewFile && ed.cache.isPopulated) {
+// Waves can be shown and interacted with: the track is decoded or its peaks were stored before
+func (ed *Editor) hasWaves() bool {
+	return !ed.Samples.IsEmpty() || ed.cache.isStored
+}
+
+// Samples to build the waves from: decoded file or its part while decoding is in progress
+func (ed *Editor) getWaveSamples() audio.Samples {
+	if !ed.Samples.IsEmpty() {
+		return ed.Samples
+	}
+	samples, _ := ed.DecodingProgress()
+	return samples
+}
+
+// Zoom range depends on the window, current zoom is kept within it
+func (ed *Editor) setScrollLimits() {
+	if ed.AudioMeta.Seconds == 0 {
 		return
 	}
-	ed.cachedFile = ed.LoadedAFile
 	ed.scroll.maxSamplesPerPx = float32(ed.AudioMeta.SampleRate) / (float32(ed.size.X) / float32(ed.AudioMeta.Seconds))
 	ed.scroll.minSamplesPerPx = ed.scroll.maxSamplesPerPx / float32(math.Exp2(float64(ed.scroll.maxLvl)))
-	ed.scroll.samplesPerPx = float32(ed.scroll.maxSamplesPerPx)
-
-	idx := 0
-	maxSamplesPerPx := int(ed.scroll.maxSamplesPerPx)
-	minSamplesPerPx := int(ed.scroll.minSamplesPerPx)
-	for i := maxSamplesPerPx; i >= minSamplesPerPx; i /= 2 {
-		ed.cache.workers[idx] = &cacheWorker{
-			samplesPerPx: i,
-			min:          1,
-			max:          -1,
-			count:        i,
-		}
-		if cap(ed.cache.peakMap[i]) == 0 {
-			ed.cache.peakMap[i] = make([][2]float32, len(ed.MonoSamples)/i)
+	ed.scroll.samplesPerPx = common.Clamp(ed.scroll.minSamplesPerPx, ed.scroll.samplesPerPx, ed.scroll.maxSamplesPerPx)
+	ed.cache.isDirty = true
+}
+
+func (ed *Editor) MakePeakMap() {
+	isNewFile := ed.cachedFile != ed.LoadedAFile
+	if isNewFile {
+		ed.cache.isPopulated = false
+		ed.cache.isStored = false
+	}
+	if !ed.HasAudioLoaded() {
+		return
+	}
+	if !isNewFile && ed.cache.isPopulated {
+		if !ed.cache.isStored {
+			ed.continuePeakMap()
 		}
-		ed.cache.peakMap[i] = ed.cache.peakMap[i][:0]
-		ed.cache.levels[idx] = i
-		idx++
+		return
+	}
+	if !ed.StoredPeaks.IsEmpty() {
+		ed.importPeaks(ed.StoredPeaks)
+	} else if !ed.getWaveSamples().IsEmpty() {
+		ed.cache.reset(ed.AudioMeta.MonoSamplesLen)
+		ed.continuePeakMap()
+	}
+	if !ed.cache.isPopulated {
+		return
+	}
+	ed.cachedFile = ed.LoadedAFile
+	ed.scroll.samplesPerPx = math.MaxFloat32
+	ed.setScrollLimits()
+}
+
+// Scans what has been decoded since the last call and stores peaks once the whole track is scanned
+func (ed *Editor) continuePeakMap() {
+	samples := ed.getWaveSamples()
+	final := !ed.Samples.IsEmpty()
+	ed.cache.extend(samples, samples.Len(), final)
+	if final && ed.StoredPeaks.IsEmpty() {
+		ed.StorePeaks(ed.exportPeaks())
 	}
-	populateCache(ed.cache.peakMap, ed.MonoSamples, ed.cache.workers)
-	ed.cache.isPopulated = true
 }
 
 func (ed *Editor) playheadPosFromX(posX float32) {
@@ -175,6 +237,7 @@ const (
 )
 
 func (ed *Editor) handleWaveScroll(scroll f32.Point, pos f32.Point) {
+	ed.suspendFollow()
 	// Zoom
 	oldSPP := ed.scroll.samplesPerPx
 	ed.scroll.samplesPerPx *= float32(math.Exp(float64(-scroll.Y * zoomRate)))
@@ -246,6 +309,92 @@ func (ed *Editor) listenToPlayerUpdates() {
 	ed.Playhead.Samples = ed.Player.GetReadAmount()
 }
 
+func (ed *Editor) cycleFollowMode() {
+	ed.scroll.follow = ed.scroll.follow.next()
+	ed.scroll.followSuspended = false
+	ed.Lg.Info("Editor: follow mode", "mode", int(ed.scroll.follow))
+}
+
+func (ed *Editor) suspendFollow() {
+	if ed.Player.IsPlaying() {
+		ed.scroll.followSuspended = true
+	}
+}
+
+// Playback (re)start resumes following, no matter which view has started it
+func (ed *Editor) updateFollowState(isPlaying bool) {
+	if isPlaying && !ed.wasPlaying {
+		ed.scroll.followSuspended = false
+	}
+	ed.wasPlaying = isPlaying
+}
+
+func (ed *Editor) followPlayhead() {
+	if ed.scroll.follow == followOff || ed.scroll.followSuspended {
+		return
+	}
+	visibleSamples := ed.scroll.rightB - ed.scroll.leftB
+	if visibleSamples <= 0 {
+		return
+	}
+	playhead := ed.Playhead.Samples
+	switch ed.scroll.follow {
+	case followPage:
+		if playhead < ed.scroll.leftB || playhead >= ed.scroll.rightB {
+			ed.scroll.leftB = playhead
+		}
+	case followCenter:
+		ed.scroll.leftB = playhead - visibleSamples/2
+	}
+}
+
+func (ed *Editor) getFollowModeLabel() string {
+	i18n := ed.I18n.Editor
+	var mode string
+	switch ed.scroll.follow {
+	case followPage:
+		mode = i18n.FollowPage
+	case followCenter:
+		mode = i18n.FollowCenter
+	default:
+		mode = i18n.FollowOff
+	}
+	return i18n.Follow + ": " + mode
+}
+
+func (ed *Editor) cycleWaveView() {
+	ed.waveView = ed.waveView.next()
+	ed.Lg.Info("Editor: wave view", "view", int(ed.waveView))
+}
+
+func (ed *Editor) toggleRMS() {
+	ed.showRMS = !ed.showRMS
+	ed.Lg.Info("Editor: RMS envelope", "show", ed.showRMS)
+}
+
+func (ed *Editor) getRMSLabel() string {
+	i18n := ed.I18n.Editor
+	state := i18n.RMSOff
+	if ed.showRMS {
+		state = i18n.RMSOn
+	}
+	return i18n.RMS + ": " + state
+}
+
+func (ed *Editor) getWaveViewLabel() string {
+	i18n := ed.I18n.Editor
+	var view string
+	switch ed.waveView {
+	case viewStereo:
+		view = i18n.ViewStereo
+	case viewMidSide:
+		view = i18n.ViewMidSide
+	default:
+		view = i18n.ViewMono
+	}
+	return i18n.View + ": " + view
+}
+
 func (ed *Editor) isCreateButtonVisible() bool {
 	correctMode := ed.mode == modeMLife || ed.mode == modeMCreateIntent || ed.mode == modeMDeleteIntent
 	return !ed.TimeMarkers.IsFull() && correctMode
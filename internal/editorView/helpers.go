@@ -1,22 +1,84 @@
 package editorview
 
-func populateCache(cache map[int][][2]float32, samples []float32, workers []*cacheWorker) {
-	for _, it := range samples {
-		for _, w := range workers {
-			if it < w.min {
-				w.min = it
-			}
-			if it > w.max {
-				w.max = it
-			}
-			w.count--
-			if w.count == 0 {
-				cache[w.samplesPerPx] = append(cache[w.samplesPerPx], [2]float32{w.min, w.max})
-				w.sliceIdx++
-				w.min = 1
-				w.max = -1
-				w.count = w.samplesPerPx
+import (
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/spyhere/re-peat/internal/audio"
+)
+
+// Bins smaller than this are not worth spreading across goroutines
+const minParallelBins = 1024
+
+// Appends bins of "spp" samples for range [from:to) to the level, the range is split between CPU cores
+func scanBins(lvl *peaks, rmsLvl *rms, samples audio.Samples, from, to, spp int) {
+	start := len(lvl[0])
+	n := (to - from + spp - 1) / spp
+	for ch := range channelsAmount {
+		lvl[ch] = slices.Grow(lvl[ch], n)[:start+n]
+		rmsLvl[ch] = slices.Grow(rmsLvl[ch], n)[:start+n]
+	}
+	workers := min(runtime.NumCPU(), max(n/minParallelBins, 1))
+	perWorker := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for w := range workers {
+		b0, b1 := w*perWorker, min((w+1)*perWorker, n)
+		if b0 >= b1 {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := b0; b < b1; b++ {
+				s0 := from + b*spp
+				scanBin(lvl, rmsLvl, start+b, samples, s0, min(s0+spp, to))
 			}
+		}()
+	}
+	wg.Wait()
+}
+
+func scanBin(lvl *peaks, rmsLvl *rms, idx int, samples audio.Samples, from, to int) {
+	var (
+		low, high [channelsAmount]float32
+		sumSq     [channelsAmount]float64
+		values    [channelsAmount]float32
+	)
+	for ch := range channelsAmount {
+		low[ch], high[ch] = 1, -1
+	}
+	for i := from; i < to; i++ {
+		values[chMid] = samples.Mid[i]
+		values[chSide] = samples.Side[i]
+		values[chLeft] = samples.Left(i)
+		values[chRight] = samples.Right(i)
+		for ch, it := range values {
+			low[ch] = min(low[ch], it)
+			high[ch] = max(high[ch], it)
+			sumSq[ch] += float64(it * it)
+		}
+	}
+	for ch := range channelsAmount {
+		lvl[ch][idx] = [2]float32{low[ch], high[ch]}
+		rmsLvl[ch][idx] = float32(math.Sqrt(sumSq[ch] / float64(to-from)))
+	}
+}
+
+// Appends to the coarse level bins made of pairs of the fine level bins that are not there yet,
+// "final" allows the last bin to be made of a single fine bin
+func reduceLevel(fine *peaks, fineRMS *rms, coarse *peaks, coarseRMS *rms, final bool) {
+	n := len(fine[0]) / 2
+	if final {
+		n = (len(fine[0]) + 1) / 2
+	}
+	for i := len(coarse[0]); i < n; i++ {
+		i0, i1 := i*2, min(i*2+2, len(fine[0]))
+		for ch := range channelsAmount {
+			low, high := reducePeaks(fine[ch][i0:i1])
+			coarse[ch] = append(coarse[ch], [2]float32{low, high})
+			coarseRMS[ch] = append(coarseRMS[ch], reduceRMS(fineRMS[ch][i0:i1]))
 		}
 	}
 }
@@ -37,3 +99,15 @@ func reducePeaks(data [][2]float32) (low float32, high float32) {
 	}
 	return low, high
 }
+
+// Combines RMS of adjacent ranges of equal length
+func reduceRMS(data []float32) float32 {
+	if len(data) == 0 {
+		return 0
+	}
+	var sumSq float32
+	for _, it := range data {
+		sumSq += it * it
+	}
+	return float32(math.Sqrt(float64(sumSq / float32(len(data)))))
+}
@@ -52,6 +52,26 @@ func (ed *Editor) handleKeyEvents(e key.Event) {
 		case key.NameRightArrow:
 			ed.collapseRenamerSelection()
 			ed.nudgePlayhead(true)
+		case "F":
+			if !ed.markers.isEditing() {
+				ed.cycleFollowMode()
+			}
+		case "V":
+			if !ed.markers.isEditing() {
+				ed.cycleWaveView()
+			}
+		case "R":
+			if !ed.markers.isEditing() {
+				ed.toggleRMS()
+			}
+		case "S":
+			if !ed.markers.isEditing() {
+				ed.cycleSpectroMode()
+			}
+		case "C":
+			if !ed.markers.isEditing() && ed.spectro.mode != spectroOff {
+				ed.cycleSpectroContrast()
+			}
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package editorview
 
 import (
+	"fmt"
 	"image"
 
 	"gioui.org/io/pointer"
@@ -13,34 +14,57 @@ import (
 func (ed *Editor) Layout(gtx layout.Context) layout.Dimensions {
 	ed.dispatch(gtx)
 	ed.updateDifferedState()
-	if ed.HasAudioLoaded() && ed.Player.IsPlaying() {
+	isPlaying := ed.HasAudioLoaded() && ed.Player.IsPlaying()
+	ed.updateFollowState(isPlaying)
+	if isPlaying {
 		if !ed.Player.IsEOF() {
-			gtx.Source.Execute(op.InvalidateCmd{At: gtx.Now.Add(ed.playheadUpd)})
+			playheadUpd := ed.playheadUpd
+			if ed.scroll.follow == followCenter && !ed.scroll.followSuspended {
+				playheadUpd = playheadMinDur
+			}
+			gtx.Source.Execute(op.InvalidateCmd{At: gtx.Now.Add(playheadUpd)})
 		}
 		ed.listenToPlayerUpdates()
+		ed.followPlayhead()
 	}
 
 	common.DrawBackground(gtx, ed.Th.Palette.Editor.Bg)
 	common.RegisterTag(gtx, &ed.tags.mLife, image.Rect(0, 0, gtx.Constraints.Max.X, ed.waveM))
 
-	if ed.HasAudioLoaded() && len(ed.MonoSamples) == 0 {
+	if ed.HasAudioLoaded() && !ed.hasWaves() {
+		if ed.IsDecoding() {
+			ed.layoutDecoding(gtx)
+			return layout.Dimensions{}
+		}
 		if ed.makeCacheCl.Clicked(gtx) {
 			ed.makeCacheCl = widget.Clickable{}
 			ed.DecodeAllSamples()
 		}
-		drawCreateCacheButton(gtx, ed.Th, &ed.makeCacheCl, ed.IsDecoding(), ed.I18n.Editor.BuildWave)
-		if ed.IsDecoding() {
-			common.DrawBlockingMessage(gtx, ed.Th, ed.I18n.Editor.BuildingWave)
-		}
+		drawCreateCacheButton(gtx, ed.Th, &ed.makeCacheCl, false, ed.I18n.Editor.BuildWave)
 		if ed.makeCacheCl.Hovered() {
 			common.SetCursor(gtx, pointer.CursorPointer)
 		}
 		return layout.Dimensions{}
 	}
 
+	ed.updateSpectrogram(gtx)
 	yCenter := gtx.Constraints.Max.Y / 2
-	offsetBy(gtx, image.Pt(-1, ed.waveM), func() {
-		soundWavesComp(gtx, ed.Th, float32(yCenter-ed.waveM), ed.getRenderableWaves(), ed.scroll, ed.cache)
+	waves := ed.getRenderableWaves()
+	var envelope rms
+	if ed.showRMS && ed.cache.isPopulated {
+		envelope = ed.cache.curRMS
+	}
+	if ed.spectro.isReady(ed.LoadedAFile) {
+		offsetBy(gtx, image.Pt(0, ed.waveM), func() {
+			spectrogramComp(gtx, ed.getSpectroImage(image.Pt(gtx.Constraints.Max.X, gtx.Constraints.Max.Y-ed.waveM*2)))
+		})
+	} else {
+		offsetBy(gtx, image.Pt(-1, ed.waveM), func() {
+			soundWavesComp(gtx, ed.Th, float32(yCenter-ed.waveM), waves, envelope, ed.waveView, ed.scroll, ed.cache)
+		})
+	}
+	offsetBy(gtx, image.Pt(0, ed.waveM), func() {
+		volumeEnvelopeComp(gtx, ed.Th, ed.VolumeEnvelope(), gtx.Constraints.Max.Y-ed.waveM*2, ed.scroll)
 	})
 	common.RegisterTag(gtx, &ed.tags.soundWave, image.Rect(0, ed.waveM, gtx.Constraints.Max.X, gtx.Constraints.Max.Y-ed.waveM))
 
@@ -49,12 +73,67 @@ func (ed *Editor) Layout(gtx layout.Context) layout.Dimensions {
 	pDim := playheadComp(gtx, ed.Th, ed.Playhead.Samples, ed.scroll)
 	markersComp(gtx, ed.Th, ed.mEditor, ed.mode, ed.waveM, ed.scroll, ed.markers, ed.getMI9n)
 	secondsGridComp(gtx, ed.Th, ed.AudioMeta, ed.scroll, ed.waveM)
+	ed.overview.area = ed.getOverviewArea(gtx)
+	common.OffsetBy(gtx, ed.overview.area.Min, func(gtx layout.Context) {
+		gtx.Constraints.Max = ed.overview.area.Size()
+		overviewComp(gtx, ed.Th, overviewProps{
+			waves:        ed.getOverviewWaves(),
+			scroll:       ed.scroll,
+			playhead:     ed.Playhead.Samples,
+			markers:      *ed.markers.arr,
+			totalSamples: ed.AudioMeta.MonoSamplesLen,
+			tag:          &ed.tags.overview,
+		})
+	})
 	if ed.markers.isEditing() {
 		editingMarkerComp(gtx, ed.Th, &ed.tags.backdrop, ed.markers.overlayParams)
 	}
 	if ed.isCreateButtonVisible() {
 		mCreateButtonComp(gtx, ed.Th, &ed.tags.mCreateButton, ed.waveM, pDim)
 	}
+	if ed.followCl.Clicked(gtx) {
+		ed.cycleFollowMode()
+	}
+	if ed.waveViewCl.Clicked(gtx) {
+		ed.cycleWaveView()
+	}
+	if ed.rmsCl.Clicked(gtx) {
+		ed.toggleRMS()
+	}
+	if ed.spectroCl.Clicked(gtx) {
+		ed.cycleSpectroMode()
+	}
+	if ed.contrastCl.Clicked(gtx) {
+		ed.cycleSpectroContrast()
+	}
+	toolbar := []toolbarItem{
+		{cl: &ed.followCl, text: ed.getFollowModeLabel()},
+		{cl: &ed.waveViewCl, text: ed.getWaveViewLabel()},
+		{cl: &ed.rmsCl, text: ed.getRMSLabel()},
+		{cl: &ed.spectroCl, text: ed.getSpectroLabel()},
+	}
+	if ed.spectro.mode != spectroOff {
+		toolbar = append(toolbar, toolbarItem{cl: &ed.contrastCl, text: ed.getSpectroContrastLabel()})
+	}
+	toolbarComp(gtx, ed.Th, ed.waveM, toolbar...)
 	common.SetCursor(gtx, ed.cursor)
+	if ed.followCl.Hovered() || ed.waveViewCl.Hovered() || ed.rmsCl.Hovered() || ed.spectroCl.Hovered() || ed.contrastCl.Hovered() {
+		common.SetCursor(gtx, pointer.CursorPointer)
+	}
 	return layout.Dimensions{}
 }
+
+// Waves fill in left to right as samples are being decoded
+func (ed *Editor) layoutDecoding(gtx layout.Context) {
+	_, progress := ed.DecodingProgress()
+	yCenter := gtx.Constraints.Max.Y / 2
+	var envelope rms
+	waves := ed.getRenderableWaves()
+	if ed.showRMS && ed.cache.isPopulated {
+		envelope = ed.cache.curRMS
+	}
+	offsetBy(gtx, image.Pt(-1, ed.waveM), func() {
+		soundWavesComp(gtx, ed.Th, float32(yCenter-ed.waveM), waves, envelope, ed.waveView, ed.scroll, ed.cache)
+	})
+	decodingProgressComp(gtx, ed.Th, ed.waveM, fmt.Sprintf(ed.I18n.Editor.BuildingWave, int(progress*100)))
+}
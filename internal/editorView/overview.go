@@ -0,0 +1,61 @@
+package editorview
+
+import (
+	"image"
+
+	"gioui.org/layout"
+	"github.com/spyhere/re-peat/internal/common"
+)
+
+// Thin strip with the whole track, used to navigate the zoomed waveform
+type overview struct {
+	area       image.Rectangle
+	grabOffset int // samples between the grabbing point and the left border of visible range
+	isGrabbed  bool
+}
+
+func (ed *Editor) getOverviewArea(gtx layout.Context) image.Rectangle {
+	sz := ed.Th.Sizing.Editor.Overview
+	y := common.PrcToPx(ed.waveM, sz.MargT)
+	return image.Rect(sz.MargX, y, gtx.Constraints.Max.X-sz.MargX, y+sz.H)
+}
+
+func (ed *Editor) getOverviewWaves() [][2]float32 {
+	if !ed.cache.isPopulated {
+		return [][2]float32{}
+	}
+	w := max(ed.overview.area.Dx(), 1)
+	return ed.cache.peakMap[ed.cache.getLevel(float32(ed.AudioMeta.MonoSamplesLen)/float32(w))][chMid]
+}
+
+func (ed *Editor) overviewSamplesFromX(x float32) int {
+	w := ed.overview.area.Dx()
+	if w == 0 {
+		return 0
+	}
+	samples := int(x * float32(ed.AudioMeta.MonoSamplesLen) / float32(w))
+	return common.Clamp(0, samples, ed.AudioMeta.MonoSamplesLen)
+}
+
+func (ed *Editor) grabOverview(x float32) {
+	samples := ed.overviewSamplesFromX(x)
+	ed.overview.isGrabbed = true
+	if samples >= ed.scroll.leftB && samples < ed.scroll.rightB {
+		ed.overview.grabOffset = samples - ed.scroll.leftB
+	} else {
+		ed.overview.grabOffset = (ed.scroll.rightB - ed.scroll.leftB) / 2
+	}
+	ed.dragOverview(x)
+}
+
+func (ed *Editor) dragOverview(x float32) {
+	if !ed.overview.isGrabbed {
+		return
+	}
+	ed.suspendFollow()
+	ed.scroll.leftB = ed.overviewSamplesFromX(x) - ed.overview.grabOffset
+}
+
+func (ed *Editor) releaseOverview() {
+	ed.overview.isGrabbed = false
+}
@@ -17,6 +17,7 @@ const (
 	hitM
 	hitMName
 	hitBackdrop
+	hitOverview
 )
 
 type hitTarget struct {
@@ -77,6 +78,17 @@ func (ed *Editor) transition(p pointerEvent) {
 		ed.mode = modeMEditIntent
 	case hitBackdrop:
 		ed.setCursor(pointer.CursorDefault)
+	case hitOverview:
+		if isDraggingMarker || isEditingMarker {
+			return
+		}
+		if ed.overview.isGrabbed {
+			ed.setCursor(pointer.CursorGrabbing)
+		} else {
+			ed.setCursor(pointer.CursorPointer)
+		}
+		ed.mode = modeOverview
+		ed.markers.stopHover()
 	}
 }
 
@@ -163,6 +175,20 @@ func (ed *Editor) handleDragMarker(p pointerEvent) {
 	ed.transition(p)
 }
 
+func (ed *Editor) handleOverview(p pointerEvent) {
+	switch p.Event.Kind {
+	case pointer.Press:
+		if p.Target.Kind == hitOverview && p.Event.Buttons == pointer.ButtonPrimary {
+			ed.grabOverview(p.Event.Position.X)
+		}
+	case pointer.Drag:
+		ed.dragOverview(p.Event.Position.X)
+	case pointer.Release:
+		ed.releaseOverview()
+	}
+	ed.transition(p)
+}
+
 func (ed *Editor) handlePointer(p pointerEvent) {
 	switch ed.mode {
 	case modeIdle:
@@ -183,5 +209,7 @@ func (ed *Editor) handlePointer(p pointerEvent) {
 		ed.handleMEdit(p)
 	case modeMDrag:
 		ed.handleDragMarker(p)
+	case modeOverview:
+		ed.handleOverview(p)
 	}
 }
@@ -5,9 +5,23 @@ const maxScrollLvl = 5
 func newScroll() scroll {
 	return scroll{
 		maxLvl: maxScrollLvl,
+		follow: followPage,
 	}
 }
 
+// How visible range should follow the playhead during playback
+type followMode int
+
+const (
+	followOff    followMode = iota
+	followPage              // jump to the next page when playhead reaches the edge
+	followCenter            // keep playhead in the center of visible range
+)
+
+func (f followMode) next() followMode {
+	return (f + 1) % (followCenter + 1)
+}
+
 type scroll struct {
 	leftB           int // left border of samples to skip what's outside of visible range
 	rightB          int // right border of samples
@@ -15,4 +29,6 @@ type scroll struct {
 	samplesPerPx    float32
 	minSamplesPerPx float32
 	maxSamplesPerPx float32
+	follow          followMode
+	followSuspended bool // manual pan or zoom suspends following until playback restarts
 }
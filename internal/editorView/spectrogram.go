@@ -0,0 +1,247 @@
+package editorview
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"github.com/spyhere/re-peat/internal/spectrogram"
+)
+
+const (
+	spectroPollDur = time.Millisecond * 100
+	spectroMinFreq = 20.0
+)
+
+type spectroMode int
+
+const (
+	spectroOff spectroMode = iota
+	spectroLinear
+	spectroLog
+)
+
+func (s spectroMode) next() spectroMode {
+	return (s + 1) % (spectroLog + 1)
+}
+
+// Magnitudes below this fraction are considered as silence, higher value gives higher contrast
+var spectroContrast = [...]float32{0, 0.2, 0.4, 0.6}
+
+type spectroResult struct {
+	file string
+	data spectrogram.Spectrogram
+	err  error
+}
+
+// Parameters that the current image was rendered with
+type spectroImgKey struct {
+	file     string
+	leftB    int
+	spp      float32
+	size     image.Point
+	mode     spectroMode
+	contrast int
+}
+
+type spectro struct {
+	mode       spectroMode
+	contrast   int // index of spectroContrast
+	file       string
+	data       spectrogram.Spectrogram
+	isBuilding bool
+	cancel     context.CancelFunc
+	resultCh   chan spectroResult
+	img        *image.RGBA
+	imgOp      paint.ImageOp
+	imgKey     spectroImgKey
+	rows       []int // bin for every row of the image
+	lut        [256]color.RGBA
+	lutFor     int // contrast the lut was made for
+}
+
+func newSpectro() spectro {
+	return spectro{
+		resultCh: make(chan spectroResult, 1),
+		lutFor:   -1,
+	}
+}
+
+func (s *spectro) isReady(file string) bool {
+	return s.mode != spectroOff && s.file == file && !s.data.IsEmpty()
+}
+
+func (ed *Editor) cycleSpectroMode() {
+	ed.spectro.mode = ed.spectro.mode.next()
+	ed.Lg.Info("Editor: spectrogram mode", "mode", int(ed.spectro.mode))
+}
+
+func (ed *Editor) cycleSpectroContrast() {
+	ed.spectro.contrast = (ed.spectro.contrast + 1) % len(spectroContrast)
+}
+
+func (ed *Editor) buildSpectrogram() {
+	if ed.spectro.cancel != nil {
+		ed.spectro.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ed.spectro.cancel = cancel
+	ed.spectro.isBuilding = true
+	file, samples, sampleRate := ed.LoadedAFile, ed.Samples.Mid, ed.AudioMeta.SampleRate
+	go func() {
+		data, err := spectrogram.Build(ctx, samples, sampleRate)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		ed.spectro.resultCh <- spectroResult{file: file, data: data, err: err}
+	}()
+}
+
+func (ed *Editor) updateSpectrogram(gtx layout.Context) {
+	select {
+	case res := <-ed.spectro.resultCh:
+		ed.spectro.isBuilding = false
+		if res.err != nil {
+			ed.Lg.Error("Editor: building spectrogram", res.err)
+		} else if res.file == ed.LoadedAFile {
+			ed.spectro.file = res.file
+			ed.spectro.data = res.data
+			ed.Lg.Info("Editor: spectrogram is built", "levels", len(res.data.Levels))
+		}
+	default:
+	}
+	if ed.spectro.mode == spectroOff || ed.Samples.IsEmpty() {
+		return
+	}
+	if ed.spectro.file != ed.LoadedAFile && !ed.spectro.isBuilding {
+		ed.buildSpectrogram()
+	}
+	if ed.spectro.isBuilding {
+		gtx.Execute(op.InvalidateCmd{At: gtx.Now.Add(spectroPollDur)})
+	}
+}
+
+func (ed *Editor) getSpectroLabel() string {
+	i18n := ed.I18n.Editor
+	var mode string
+	switch ed.spectro.mode {
+	case spectroLinear:
+		mode = i18n.SpectroLinear
+	case spectroLog:
+		mode = i18n.SpectroLog
+	default:
+		mode = i18n.SpectroOff
+	}
+	if ed.spectro.isBuilding {
+		mode += "..."
+	}
+	return i18n.Spectrogram + ": " + mode
+}
+
+func (ed *Editor) getSpectroContrastLabel() string {
+	return ed.I18n.Editor.Contrast + ": " + strconv.Itoa(ed.spectro.contrast+1)
+}
+
+// Renders visible range of spectrogram into the image, reusing previous one if nothing has changed
+func (ed *Editor) getSpectroImage(size image.Point) paint.ImageOp {
+	s := &ed.spectro
+	key := spectroImgKey{
+		file:     s.file,
+		leftB:    ed.scroll.leftB,
+		spp:      ed.scroll.samplesPerPx,
+		size:     size,
+		mode:     s.mode,
+		contrast: s.contrast,
+	}
+	if key == s.imgKey && s.img != nil {
+		return s.imgOp
+	}
+	if s.img == nil || !s.img.Bounds().Size().Eq(size) {
+		s.img = image.NewRGBA(image.Rectangle{Max: size})
+	}
+	if key.size != s.imgKey.size || key.mode != s.imgKey.mode || key.file != s.imgKey.file {
+		s.rows = makeSpectroRows(s.rows, s.data, size.Y, s.mode)
+	}
+	if s.lutFor != s.contrast {
+		p := ed.Th.Palette.Editor.Spectrogram
+		s.lut = makeSpectroLut([3]color.NRGBA{p.Low, p.Mid, p.High}, spectroContrast[s.contrast])
+		s.lutFor = s.contrast
+	}
+	renderSpectrogram(s.img, s.data, s.rows, &s.lut, ed.scroll)
+	s.imgKey = key
+	s.imgOp = paint.NewImageOp(s.img)
+	s.imgOp.Filter = paint.FilterNearest
+	return s.imgOp
+}
+
+func makeSpectroRows(rows []int, data spectrogram.Spectrogram, h int, mode spectroMode) []int {
+	rows = rows[:0]
+	maxFreq := data.Nyquist()
+	for y := range h {
+		// 0 is the top row, so it has the highest frequency
+		t := float64(h-1-y) / float64(max(h-1, 1))
+		var freq float64
+		if mode == spectroLog {
+			freq = spectroMinFreq * math.Pow(maxFreq/spectroMinFreq, t)
+		} else {
+			freq = t * maxFreq
+		}
+		rows = append(rows, data.BinFromFreq(freq))
+	}
+	return rows
+}
+
+func makeSpectroLut(stops [3]color.NRGBA, floor float32) [256]color.RGBA {
+	var lut [256]color.RGBA
+	for i := range lut {
+		v := (float32(i)/255 - floor) / (1 - floor)
+		v = min(max(v, 0), 1)
+		from, to, t := stops[0], stops[1], v*2
+		if v > 0.5 {
+			from, to, t = stops[1], stops[2], (v-0.5)*2
+		}
+		lut[i] = color.RGBA{
+			R: lerpU8(from.R, to.R, t),
+			G: lerpU8(from.G, to.G, t),
+			B: lerpU8(from.B, to.B, t),
+			A: 0xff,
+		}
+	}
+	return lut
+}
+
+func lerpU8(a, b uint8, t float32) uint8 {
+	return uint8(float32(a) + (float32(b)-float32(a))*t)
+}
+
+func renderSpectrogram(img *image.RGBA, data spectrogram.Spectrogram, rows []int, lut *[256]color.RGBA, s scroll) {
+	size := img.Bounds().Size()
+	lvl := data.GetLevel(s.samplesPerPx)
+	colsN := len(lvl.Columns)
+	for x := range size.X {
+		sample0 := s.leftB + int(float32(x)*s.samplesPerPx)
+		sample1 := s.leftB + int(float32(x+1)*s.samplesPerPx)
+		c0 := sample0 / lvl.Hop
+		c1 := max(c0+1, sample1/lvl.Hop)
+		c0, c1 = min(c0, colsN), min(c1, colsN)
+		for y, bin := range rows {
+			var v uint8
+			for c := c0; c < c1; c++ {
+				v = max(v, lvl.Columns[c][bin])
+			}
+			off := img.PixOffset(x, y)
+			col := lut[v]
+			img.Pix[off+0] = col.R
+			img.Pix[off+1] = col.G
+			img.Pix[off+2] = col.B
+			img.Pix[off+3] = col.A
+		}
+	}
+}
@@ -6,6 +6,7 @@ type tags struct {
 	noneArea      *struct{}
 	mCreateButton *struct{}
 	backdrop      *struct{}
+	overview      *struct{}
 }
 
 func newTags() *tags {
@@ -15,5 +16,6 @@ func newTags() *tags {
 		noneArea:      &struct{}{},
 		mCreateButton: &struct{}{},
 		backdrop:      &struct{}{},
+		overview:      &struct{}{},
 	}
 }
@@ -2,6 +2,7 @@ package filemanager
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"gioui.org/app"
@@ -79,6 +80,14 @@ type namer interface {
 }
 
 func (f *FileManager) SaveAs(defaultName string, data []byte, cb func(string, error)) {
+	f.SaveAsStream(defaultName, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}, cb)
+}
+
+// Same as SaveAs, but "write" produces the content, so big files don't have to be kept in memory
+func (f *FileManager) SaveAsStream(defaultName string, write func(io.Writer) error, cb func(string, error)) {
 	go func(cb func(string, error)) {
 		wc, err := f.e.CreateFile(defaultName)
 		defer func() {
@@ -90,6 +99,10 @@ func (f *FileManager) SaveAs(defaultName string, data []byte, cb func(string, er
 				filePath = n.Name()
 			}
 			if err != nil {
+				// Half written file is of no use
+				if filePath != "" {
+					os.Remove(filePath)
+				}
 				cb("", err)
 			} else {
 				cb(filePath, nil)
@@ -100,10 +113,7 @@ func (f *FileManager) SaveAs(defaultName string, data []byte, cb func(string, er
 		if err != nil {
 			return
 		}
-		_, err = wc.Write(data)
-		if err != nil {
-			return
-		}
+		err = write(wc)
 	}(cb)
 }
 
@@ -8,5 +8,7 @@ type MarkersSaveScheme struct {
 	FSize   int64
 	FLen    float64
 	FSRate  int
+	Gain    *float64 `json:",omitempty"` // track normalisation in dB
+	Tempo   *float64 `json:",omitempty"` // project tempo in bpm
 	Markers timemarkers.TimeMarkers
 }
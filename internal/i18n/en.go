@@ -2,57 +2,159 @@ package i18n
 
 var enStr = Strings{
 	Common: Common{
-		CrashFoundBody:  "On startup, the app found %d crash report(s) on your Desktop:\n%s\n\nPlease share these files with the developer to help diagnose the issue.\n\nThis message will continue to appear on startup while these crash reports are present. You can remove them after sending.",
-		CrashFoundTitle: "App closed unexpectedly",
-		InfoDialogOk:    "Got it!",
-		LogsDumpedBody:  "An error log file \"%s.json\" has been saved on your Desktop.\nPlease share this file with the developer to help diagnose the issue.",
-		LogsDumpedTitle: "Unexpected error happened",
-		NewUpdateCancel: "Remind me later",
-		NewUpdateOk:     "Download from browser (%s)",
-		NewUpdateRead:   "Read in browser",
-		NewUpdateTitle:  "New version released - %s (%s)",
+		CrashFoundBody:     "On startup, the app found %d crash report(s) on your Desktop:\n%s\n\nPlease share these files with the developer to help diagnose the issue.\n\nThis message will continue to appear on startup while these crash reports are present. You can remove them after sending.",
+		CrashFoundTitle:    "App closed unexpectedly",
+		DecodeBadHeader:    "\"%s\" looks like a %s file, but its header is damaged or incomplete.",
+		DecodeChannels:     "\"%s\" has %d audio channels. Only files with 1 to %d channels are supported.",
+		DecodeCorrupted:    "\"%s\" contains damaged %s audio data and can't be decoded to the end.",
+		DecodeErrorTitle:   "Can't open the audio file",
+		DecodeTruncated:    "\"%s\" ends unexpectedly. It was probably not fully downloaded or copied.",
+		DecodeUnknown:      "\"%s\" is not an audio file the app recognises.\n\nSupported formats: %s.",
+		DecodeUnsupported:  "\"%s\" is %s audio, which can't be decoded yet.\n\nSupported formats: %s.",
+		InfoDialogOk:       "Got it!",
+		LogsDumpedBody:     "An error log file \"%s.json\" has been saved on your Desktop.\nPlease share this file with the developer to help diagnose the issue.",
+		LogsDumpedTitle:    "Unexpected error happened",
+		NewUpdateCancel:    "Remind me later",
+		NewUpdateOk:        "Download from browser (%s)",
+		NewUpdateRead:      "Read in browser",
+		NewUpdateTitle:     "New version released - %s (%s)",
+		OutputStalledBody:  "The audio output stopped playing, the device may have been disconnected.\nPlayback was paused at %s.\n\nThe app always plays through the system default output device. Reconnect the device or choose another default output in system settings, then resume playback.",
+		OutputStalledTitle: "Audio output stopped",
 	},
 	Generic: Generic{
-		Amount:        "Amount",
-		Audio:         "Audio",
-		AudioChannels: "Audio Channels",
-		Cancel:        "Cancel",
-		Editor:        "Editor",
-		Length:        "Length",
-		Markers:       "Markers",
-		Modified:      "Modified",
-		Mono:          "Mono",
-		Name:          "Name",
-		Notes:         "Notes",
-		Ok:            "OK",
-		Project:       "Project",
-		SampleRate:    "Sample Rate",
-		Save:          "Save",
-		SaveAs:        "Save As",
-		Size:          "Size",
-		Stereo:        "Stereo",
-		Tags:          "Tags",
-		Time:          "Time",
-		WithComments:  "With comments",
+		Amount:          "Amount",
+		Audio:           "Audio",
+		AudioChannels:   "Audio Channels",
+		Cancel:          "Cancel",
+		Editor:          "Editor",
+		Length:          "Length",
+		LoudestSection:  "Loudest section",
+		Loudness:        "Loudness",
+		Markers:         "Markers",
+		Modified:        "Modified",
+		Mono:            "Mono",
+		MultiChannel:    "%d channels (first 2 displayed)",
+		Name:            "Name",
+		Normalisation:   "Normalisation",
+		Notes:           "Notes",
+		Ok:              "OK",
+		Project:         "Project",
+		QuietestSection: "Quietest section",
+		SampleRate:      "Sample Rate",
+		Save:            "Save",
+		SaveAs:          "Save As",
+		Size:            "Size",
+		Stereo:          "Stereo",
+		Tags:            "Tags",
+		Tempo:           "Tempo",
+		Time:            "Time",
+		TruePeak:        "True peak",
+		WithComments:    "With comments",
 	},
 	Markers: MarkersView{
 		MCreate:            "Create marker",
 		MDeleteALlBody:     "This action will remove all markers for current audio track!",
 		MDeleteALlTitle:    "Delete all markers",
 		MEdit:              "Edit marker",
+		MGain:              "Volume, dB",
 		MNamePlaceholder:   "marker's name...",
-		MNotePlaceholder:   "This was fabulous!",
 		MNote:              "Notes",
+		MNotePlaceholder:   "This was fabulous!",
+		MRamp:              "Ramp, s",
+		MShiftAlign:        "Compare with previous version",
+		MShiftAligning:     "Comparing with \"%s\"...",
+		MShiftFailed:       "Could not compare with \"%s\"",
+		MShiftFound:        "Found %+.3f s against \"%s\", match %d%%",
+		MShiftNoMatch:      "\"%s\" doesn't match the loaded audio",
+		MShiftOffset:       "Offset, s",
+		MShiftOutOfTrack:   "out of track, will be redacted",
+		MShiftTitle:        "Shift all markers",
 		NoMatches:          "no matches, refine filters",
 		SearchBPlaceholder: "Search by name...",
 		TagsFilter:         "Tags filter",
 	},
 	Project: ProjectView{
-		MConflictLoadBody:  "These markers were initially saved for \"%s\", but currently loaded \"%s\".\nStill want to load them for this audio file?\n\nMarkers exceeding audio length will be set to 0 and have \"Redacted\" tag added.",
-		MConflictLoadTitle: "Markers loading conflict",
+		AfterRestart:        "after restart",
+		Buffer:              "Buffer",
+		BundleBrokenBody:    "\"%s\" can't be opened, it's damaged or was changed after saving:\n%v",
+		BundleBrokenTitle:   "Project bundle",
+		BundleSave:          "Save with audio",
+		Compare:             "Compare with the file",
+		DiffAdded:           "Added",
+		DiffAutomation:      "Volume automation changed",
+		DiffGain:            "%s dB in %s s",
+		DiffMoved:           "Moved by %s s",
+		DiffNoGain:          "no volume change",
+		DiffNotes:           "Notes changed",
+		DiffNothing:         "The markers are the same as in \"%s\".",
+		DiffRemoved:         "Removed",
+		DiffRenamed:         "Renamed",
+		DiffResized:         "Region end moved",
+		DiffRetagged:        "Tags changed",
+		DiffRevert:          "Revert",
+		DiffTitle:           "Changes since \"%s\"",
+		Export:              "Export",
+		ExportDropped:       "The format can't hold that many markers, these are left out: %s.",
+		ExportFilterSearch:  "Search: \"%s\"",
+		ExportFilterTags:    "Tags: %s",
+		ExportFiltered:      "Only shown markers: %d of %d",
+		ExportIssuesTitle:   "Not everything fits into %s",
+		ExportNonASCII:      "Names of markers %s have characters outside of ASCII, like Cyrillic or accented letters. CD burners and players may show them garbled.",
+		ExportNotes:         "Include notes",
+		ExportPage:          "%d / %d",
+		ExportReplaced:      "Quotes and line breaks are replaced in the names of markers %s.",
+		ExportRounded:       "%d markers are moved to the nearest frame the format can store, by up to %d ms.",
+		ExportTempo:         "Tempo, BPM",
+		ExportThumbnails:    "Waveform thumbnails",
+		ExportTitle:         "Export markers",
+		ExportTruncated:     "Names are cut to the length the format allows for markers %s.",
+		Import:              "Import",
+		ImportColumnsTitle:  "Match the columns",
+		ImportEmbeddedBody:  "\"%[2]s\" has %[1]d markers written into it. Import them?",
+		ImportEmbeddedTitle: "Markers in the audio",
+		ImportMerge:         "Merge",
+		ImportMergeBody:     "%d markers were read from \"%s\".\nMerge them with the current %d markers or replace them?",
+		ImportMergeTitle:    "Import markers",
+		ImportNoTime:        "Nothing was imported: choose the column with time or with samples.",
+		ImportOverLimit:     "%d markers didn't fit, no more than %d markers are allowed.",
+		ImportReplace:       "Replace",
+		ImportReportBody:    "%d markers imported from \"%s\".",
+		ImportReportTitle:   "Markers import",
+		ImportSamples:       "Samples",
+		ImportSkipped:       "%d lines were skipped:\n%s",
+		Latency:             "latency",
+		MConflictLoadBody:   "These markers were initially saved for \"%s\", but currently loaded \"%s\".\nStill want to load them for this audio file?\n\nMarkers exceeding audio length will be set to 0 and have \"Redacted\" tag added.",
+		MConflictLoadTitle:  "Markers loading conflict",
+		MergeAbsent:         "Not there",
+		MergeCurrent:        "Current markers",
+		MergeFrom:           "From \"%s\"",
+		MergeNothing:        "Markers in the file are the same as the current ones.",
+		MergeSummary:        "%d only in the file, %d only in the current markers, %d differ. Pick the side to keep for every marker.",
+		MergeTitle:          "Merge markers",
+		OutputRate:          "Output",
+		Resampler:           "Resampler",
+		ResamplerFast:       "Fast",
+		ResamplerHigh:       "High",
+		ResamplerStandard:   "Standard",
 	},
 	Editor: EditorView{
-		BuildWave:    "Generate waveform",
-		BuildingWave: "Generating waveform...",
+		BuildWave:     "Generate waveform",
+		BuildingWave:  "Generating waveform... %d%%",
+		Contrast:      "Contrast",
+		Follow:        "Follow",
+		FollowCenter:  "Center",
+		FollowOff:     "Off",
+		FollowPage:    "Page",
+		RMS:           "RMS",
+		RMSOff:        "Off",
+		RMSOn:         "On",
+		SpectroLinear: "Linear",
+		SpectroLog:    "Log",
+		SpectroOff:    "Off",
+		Spectrogram:   "Spectrogram",
+		View:          "View",
+		ViewMidSide:   "Mid / Side",
+		ViewMono:      "Mono",
+		ViewStereo:    "L / R",
 	},
 }
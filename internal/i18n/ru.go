@@ -2,57 +2,159 @@ package i18n
 
 var ruStr = Strings{
 	Common: Common{
-		CrashFoundBody:  "При запуске приложение обнаружило %d отчёт(ов) о сбое на Рабочем столе:\n%s\n\nПожалуйста, отправьте эти файлы разработчику, чтобы помочь диагностировать проблему.\n\nЭто сообщение будет показываться при запуске, пока существуют эти отчёты о сбое. Вы можете удалить их после отправки.",
-		CrashFoundTitle: "Приложение завершилось неожиданно",
-		InfoDialogOk:    "Понятно",
-		LogsDumpedBody:  "Файл логов с ошибками \"%s.json\" был сохранён на Рабочем столе.\nПожалуйста, отправьте этот файл разработчику, чтобы помочь диагностировать проблему.",
-		LogsDumpedTitle: "Произошла непредвиденная ошибка",
-		NewUpdateCancel: "Напомнить позже",
-		NewUpdateOk:     "Скачать в браузере (%s)",
-		NewUpdateRead:   "Открыть в браузере",
-		NewUpdateTitle:  "Вышла новая версия - %s (%s)",
+		CrashFoundBody:     "При запуске приложение обнаружило %d отчёт(ов) о сбое на Рабочем столе:\n%s\n\nПожалуйста, отправьте эти файлы разработчику, чтобы помочь диагностировать проблему.\n\nЭто сообщение будет показываться при запуске, пока существуют эти отчёты о сбое. Вы можете удалить их после отправки.",
+		CrashFoundTitle:    "Приложение завершилось неожиданно",
+		DecodeBadHeader:    "\"%s\" похож на файл %s, но его заголовок повреждён или неполон.",
+		DecodeChannels:     "В \"%s\" %d аудиоканалов. Поддерживаются только файлы с числом каналов от 1 до %d.",
+		DecodeCorrupted:    "\"%s\" содержит повреждённые аудиоданные %s и не может быть декодирован до конца.",
+		DecodeErrorTitle:   "Не удалось открыть аудиофайл",
+		DecodeTruncated:    "\"%s\" неожиданно обрывается. Вероятно, он был скачан или скопирован не полностью.",
+		DecodeUnknown:      "\"%s\" не является аудиофайлом, который приложение может распознать.\n\nПоддерживаемые форматы: %s.",
+		DecodeUnsupported:  "\"%s\" содержит аудио %s, которое пока не может быть декодировано.\n\nПоддерживаемые форматы: %s.",
+		InfoDialogOk:       "Понятно",
+		LogsDumpedBody:     "Файл логов с ошибками \"%s.json\" был сохранён на Рабочем столе.\nПожалуйста, отправьте этот файл разработчику, чтобы помочь диагностировать проблему.",
+		LogsDumpedTitle:    "Произошла непредвиденная ошибка",
+		NewUpdateCancel:    "Напомнить позже",
+		NewUpdateOk:        "Скачать в браузере (%s)",
+		NewUpdateRead:      "Открыть в браузере",
+		NewUpdateTitle:     "Вышла новая версия - %s (%s)",
+		OutputStalledBody:  "Аудиовыход перестал воспроизводить звук, возможно, устройство было отключено.\nВоспроизведение поставлено на паузу на %s.\n\nПриложение всегда воспроизводит звук через устройство вывода по умолчанию. Подключите устройство снова или выберите другой вывод по умолчанию в настройках системы, затем продолжите воспроизведение.",
+		OutputStalledTitle: "Аудиовыход остановлен",
 	},
 	Generic: Generic{
-		Amount:        "Количество",
-		Audio:         "Аудио",
-		AudioChannels: "Аудио каналы",
-		Cancel:        "Отмена",
-		Editor:        "Редактор",
-		Length:        "Длина",
-		Markers:       "Маркера",
-		Modified:      "Изменён",
-		Mono:          "Моно",
-		Name:          "Имя",
-		Notes:         "Заметки",
-		Ok:            "OK",
-		Project:       "Проект",
-		SampleRate:    "Частота сэмплов",
-		Save:          "Сохранить",
-		SaveAs:        "Сохранить как",
-		Size:          "Размер",
-		Stereo:        "Стерео",
-		Tags:          "Категории",
-		Time:          "Время",
-		WithComments:  "С комментариями",
+		Amount:          "Количество",
+		Audio:           "Аудио",
+		AudioChannels:   "Аудио каналы",
+		Cancel:          "Отмена",
+		Editor:          "Редактор",
+		Length:          "Длина",
+		LoudestSection:  "Самая громкая секция",
+		Loudness:        "Громкость",
+		Markers:         "Маркера",
+		Modified:        "Изменён",
+		Mono:            "Моно",
+		MultiChannel:    "%d каналов (показаны первые 2)",
+		Name:            "Имя",
+		Normalisation:   "Нормализация",
+		Notes:           "Заметки",
+		Ok:              "OK",
+		Project:         "Проект",
+		QuietestSection: "Самая тихая секция",
+		SampleRate:      "Частота сэмплов",
+		Save:            "Сохранить",
+		SaveAs:          "Сохранить как",
+		Size:            "Размер",
+		Stereo:          "Стерео",
+		Tags:            "Категории",
+		Tempo:           "Темп",
+		Time:            "Время",
+		TruePeak:        "Истинный пик",
+		WithComments:    "С комментариями",
 	},
 	Markers: MarkersView{
 		MCreate:            "Создать маркер",
 		MDeleteALlBody:     "Это действие удалит все существующие маркеры для этой звуковой дорожки!",
 		MDeleteALlTitle:    "Удалить все маркеры?",
 		MEdit:              "Редактировать маркер",
+		MGain:              "Громкость, дБ",
 		MNamePlaceholder:   "имя маркера...",
 		MNote:              "Заметки",
 		MNotePlaceholder:   "Это было прекрасно!",
+		MRamp:              "Переход, с",
+		MShiftAlign:        "Сравнить с прошлой версией",
+		MShiftAligning:     "Сравнение с \"%s\"...",
+		MShiftFailed:       "Не удалось сравнить с \"%s\"",
+		MShiftFound:        "Найдено %+.3f с относительно \"%s\", совпадение %d%%",
+		MShiftNoMatch:      "\"%s\" не совпадает с загруженным аудио",
+		MShiftOffset:       "Сдвиг, с",
+		MShiftOutOfTrack:   "вне дорожки, будет помечен",
+		MShiftTitle:        "Сдвинуть все маркеры",
 		NoMatches:          "нет совпадений, уточните фильтры",
 		SearchBPlaceholder: "Название маркера...",
 		TagsFilter:         "Фильтр категорий",
 	},
 	Project: ProjectView{
-		MConflictLoadBody:  "Изначально эти маркера были сохранены для \"%s\", но сейчас загружен \"%s\".\nВсё еще хотите загрузить эти маркера для этого аудио файла?\n\nМаркера превышающие длину трека будут сброшены на 0 и получат категорию \"Изменён\"",
-		MConflictLoadTitle: "Конфликт загрузки маркеров",
+		AfterRestart:        "после перезапуска",
+		Buffer:              "Буфер",
+		BundleBrokenBody:    "\"%s\" не открыть: файл повреждён или изменён после сохранения:\n%v",
+		BundleBrokenTitle:   "Пакет проекта",
+		BundleSave:          "Сохранить с аудио",
+		Compare:             "Сравнить с файлом",
+		DiffAdded:           "Добавлен",
+		DiffAutomation:      "Изменена автоматизация громкости",
+		DiffGain:            "%s дБ за %s с",
+		DiffMoved:           "Сдвинут на %s с",
+		DiffNoGain:          "без изменения громкости",
+		DiffNotes:           "Изменены заметки",
+		DiffNothing:         "Маркеры совпадают с \"%s\".",
+		DiffRemoved:         "Удалён",
+		DiffRenamed:         "Переименован",
+		DiffResized:         "Сдвинут конец региона",
+		DiffRetagged:        "Изменены теги",
+		DiffRevert:          "Вернуть",
+		DiffTitle:           "Изменения с \"%s\"",
+		Export:              "Экспорт",
+		ExportDropped:       "Формат не вмещает столько маркеров, не попадут: %s.",
+		ExportFilterSearch:  "Поиск: \"%s\"",
+		ExportFilterTags:    "Теги: %s",
+		ExportFiltered:      "Только показанные маркеры: %d из %d",
+		ExportIssuesTitle:   "Не всё поместится в %s",
+		ExportNonASCII:      "В названиях маркеров %s есть символы вне ASCII, например кириллица или буквы с диакритикой. Программы записи CD и плееры могут показать их неправильно.",
+		ExportNotes:         "С заметками",
+		ExportPage:          "%d из %d",
+		ExportReplaced:      "Кавычки и переносы строк будут заменены в названиях маркеров %s.",
+		ExportRounded:       "Маркеров будет сдвинуто к ближайшему кадру формата: %d, не больше чем на %d мс.",
+		ExportTempo:         "Темп, BPM",
+		ExportThumbnails:    "Миниатюры волны",
+		ExportTitle:         "Экспорт маркеров",
+		ExportTruncated:     "Названия будут обрезаны до допустимой длины у маркеров %s.",
+		Import:              "Импорт",
+		ImportColumnsTitle:  "Сопоставьте столбцы",
+		ImportEmbeddedBody:  "В \"%[2]s\" записаны маркеры: %[1]d. Импортировать их?",
+		ImportEmbeddedTitle: "Маркеры в аудио",
+		ImportMerge:         "Объединить",
+		ImportMergeBody:     "Из \"%[2]s\" прочитано маркеров: %[1]d.\nОбъединить их с текущими (%[3]d) или заменить?",
+		ImportMergeTitle:    "Импорт маркеров",
+		ImportNoTime:        "Ничего не импортировано: выберите столбец со временем или с сэмплами.",
+		ImportOverLimit:     "Не поместилось маркеров: %d, допускается не больше %d.",
+		ImportReplace:       "Заменить",
+		ImportReportBody:    "Импортировано маркеров: %d из \"%s\".",
+		ImportReportTitle:   "Импорт маркеров",
+		ImportSamples:       "Сэмплы",
+		ImportSkipped:       "Пропущено строк: %d\n%s",
+		Latency:             "задержка",
+		MConflictLoadBody:   "Изначально эти маркера были сохранены для \"%s\", но сейчас загружен \"%s\".\nВсё еще хотите загрузить эти маркера для этого аудио файла?\n\nМаркера превышающие длину трека будут сброшены на 0 и получат категорию \"Изменён\"",
+		MConflictLoadTitle:  "Конфликт загрузки маркеров",
+		MergeAbsent:         "Нет",
+		MergeCurrent:        "Текущие маркеры",
+		MergeFrom:           "Из \"%s\"",
+		MergeNothing:        "Маркеры в файле совпадают с текущими.",
+		MergeSummary:        "Только в файле: %d, только в текущих: %d, различаются: %d. Выберите, что оставить для каждого маркера.",
+		MergeTitle:          "Объединение маркеров",
+		OutputRate:          "Вывод",
+		Resampler:           "Ресэмплер",
+		ResamplerFast:       "Быстрый",
+		ResamplerHigh:       "Высокий",
+		ResamplerStandard:   "Стандартный",
 	},
 	Editor: EditorView{
-		BuildWave:    "Создать форму волны",
-		BuildingWave: "Создание формы волны...",
+		BuildWave:     "Создать форму волны",
+		BuildingWave:  "Создание формы волны... %d%%",
+		Contrast:      "Контраст",
+		Follow:        "Следование",
+		FollowCenter:  "По центру",
+		FollowOff:     "Выкл",
+		FollowPage:    "Постранично",
+		RMS:           "RMS",
+		RMSOff:        "Выкл",
+		RMSOn:         "Вкл",
+		SpectroLinear: "Линейная",
+		SpectroLog:    "Лог.",
+		SpectroOff:    "Выкл",
+		Spectrogram:   "Спектрограмма",
+		View:          "Вид",
+		ViewMidSide:   "Mid / Side",
+		ViewMono:      "Моно",
+		ViewStereo:    "L / R",
 	},
 }
@@ -9,44 +9,120 @@ type Strings struct {
 }
 
 type Generic struct {
-	Amount        string
-	Audio         string
-	AudioChannels string
-	Cancel        string
-	Editor        string
-	Length        string
-	Markers       string
-	Modified      string
-	Mono          string
-	Name          string
-	Notes         string
-	Ok            string
-	Project       string
-	SampleRate    string
-	Save          string
-	SaveAs        string
-	Size          string
-	Stereo        string
-	Tags          string
-	Time          string
-	WithComments  string
+	Amount          string
+	Audio           string
+	AudioChannels   string
+	Cancel          string
+	Editor          string
+	Length          string
+	LoudestSection  string
+	Loudness        string
+	Markers         string
+	Modified        string
+	Mono            string
+	MultiChannel    string
+	Name            string
+	Normalisation   string
+	Notes           string
+	Ok              string
+	Project         string
+	QuietestSection string
+	SampleRate      string
+	Save            string
+	SaveAs          string
+	Size            string
+	Stereo          string
+	Tags            string
+	Tempo           string
+	Time            string
+	TruePeak        string
+	WithComments    string
 }
 
 type Common struct {
-	CrashFoundBody  string
-	CrashFoundTitle string
-	InfoDialogOk    string
-	LogsDumpedBody  string
-	LogsDumpedTitle string
-	NewUpdateCancel string
-	NewUpdateOk     string
-	NewUpdateRead   string
-	NewUpdateTitle  string
+	CrashFoundBody     string
+	CrashFoundTitle    string
+	DecodeBadHeader    string
+	DecodeChannels     string
+	DecodeCorrupted    string
+	DecodeErrorTitle   string
+	DecodeTruncated    string
+	DecodeUnknown      string
+	DecodeUnsupported  string
+	InfoDialogOk       string
+	LogsDumpedBody     string
+	LogsDumpedTitle    string
+	NewUpdateCancel    string
+	NewUpdateOk        string
+	NewUpdateRead      string
+	NewUpdateTitle     string
+	OutputStalledBody  string
+	OutputStalledTitle string
 }
 
 type ProjectView struct {
-	MConflictLoadBody  string
-	MConflictLoadTitle string
+	AfterRestart        string
+	Buffer              string
+	BundleBrokenBody    string
+	BundleBrokenTitle   string
+	BundleSave          string
+	Compare             string
+	DiffAdded           string
+	DiffAutomation      string
+	DiffGain            string
+	DiffMoved           string
+	DiffNoGain          string
+	DiffNotes           string
+	DiffNothing         string
+	DiffRemoved         string
+	DiffRenamed         string
+	DiffResized         string
+	DiffRetagged        string
+	DiffRevert          string
+	DiffTitle           string
+	Export              string
+	ExportDropped       string
+	ExportFilterSearch  string
+	ExportFilterTags    string
+	ExportFiltered      string
+	ExportIssuesTitle   string
+	ExportNonASCII      string
+	ExportNotes         string
+	ExportPage          string
+	ExportReplaced      string
+	ExportRounded       string
+	ExportTempo         string
+	ExportThumbnails    string
+	ExportTitle         string
+	ExportTruncated     string
+	Import              string
+	ImportColumnsTitle  string
+	ImportEmbeddedBody  string
+	ImportEmbeddedTitle string
+	ImportMerge         string
+	ImportMergeBody     string
+	ImportMergeTitle    string
+	ImportNoTime        string
+	ImportOverLimit     string
+	ImportReplace       string
+	ImportReportBody    string
+	ImportReportTitle   string
+	ImportSamples       string
+	ImportSkipped       string
+	Latency             string
+	MConflictLoadBody   string
+	MConflictLoadTitle  string
+	MergeAbsent         string
+	MergeCurrent        string
+	MergeFrom           string
+	MergeNothing        string
+	MergeSummary        string
+	MergeTitle          string
+	OutputRate          string
+	Resampler           string
+	ResamplerFast       string
+	ResamplerHigh       string
+	ResamplerStandard   string
 }
 
 type MarkersView struct {
@@ -54,15 +130,41 @@ type MarkersView struct {
 	MDeleteALlBody     string
 	MDeleteALlTitle    string
 	MEdit              string
+	MGain              string
 	MNamePlaceholder   string
 	MNote              string
 	MNotePlaceholder   string
+	MRamp              string
+	MShiftAlign        string
+	MShiftAligning     string
+	MShiftFailed       string
+	MShiftFound        string
+	MShiftNoMatch      string
+	MShiftOffset       string
+	MShiftOutOfTrack   string
+	MShiftTitle        string
 	NoMatches          string
 	SearchBPlaceholder string
 	TagsFilter         string
 }
 
 type EditorView struct {
-	BuildWave    string
-	BuildingWave string
+	BuildWave     string
+	BuildingWave  string
+	Contrast      string
+	Follow        string
+	FollowCenter  string
+	FollowOff     string
+	FollowPage    string
+	RMS           string
+	RMSOff        string
+	RMSOn         string
+	SpectroLinear string
+	SpectroLog    string
+	SpectroOff    string
+	Spectrogram   string
+	View          string
+	ViewMidSide   string
+	ViewMono      string
+	ViewStereo    string
 }
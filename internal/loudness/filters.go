@@ -0,0 +1,86 @@
+package loudness
+
+import "math"
+
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	z1, z2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+// K-weighting from ITU-R BS.1770: a high shelf followed by a high pass.
+// Coefficients are derived for any sample rate, so they are not limited to 48 kHz.
+type kFilter struct {
+	shelf    biquad
+	highPass biquad
+}
+
+func newKFilter(sampleRate int) kFilter {
+	fs := float64(sampleRate)
+
+	f0 := 1681.974450955533
+	gain := 3.999843853973347
+	q := 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return kFilter{shelf: shelf, highPass: highPass}
+}
+
+func (k *kFilter) process(x float64) float64 {
+	return k.highPass.process(k.shelf.process(x))
+}
+
+const (
+	oversample = 4
+	tpHalfLen  = 6 // taps on each side of the interpolated point
+)
+
+// Windowed sinc taps for every fractional phase of 4x oversampling.
+// Phase 0 is the original sample, so it is not stored.
+func newTruePeakTaps() [oversample - 1][tpHalfLen * 2]float64 {
+	var taps [oversample - 1][tpHalfLen * 2]float64
+	for p := range oversample - 1 {
+		frac := float64(p+1) / oversample
+		for k := range tpHalfLen * 2 {
+			t := float64(k-tpHalfLen+1) - frac
+			w := 0.5 + 0.5*math.Cos(math.Pi*t/tpHalfLen)
+			taps[p][k] = sinc(t) * w
+		}
+	}
+	return taps
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
@@ -0,0 +1,262 @@
+package loudness
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/spyhere/re-peat/internal/audio"
+)
+
+const (
+	stepSec       = 0.1 // gating blocks overlap by 75%
+	stepsPerBlock = 4   // 400 ms gating block
+	absoluteGate  = -70.0
+	relativeGate  = -10.0
+)
+
+// Result of the whole track scan. Any range can be measured from it without touching samples again.
+type Analysis struct {
+	Step   int       // samples per step
+	Track  Stats     // the whole track
+	blocks []float64 // K-weighted mean square of the block starting at step i
+	peaks  []float32 // true peak (linear) inside step i
+}
+
+func (a Analysis) IsEmpty() bool {
+	return a.Step == 0
+}
+
+// Integrated loudness (ITU-R BS.1770) and true peak of samples in [from, to)
+func (a Analysis) Measure(from, to int) Stats {
+	if a.IsEmpty() || to <= from {
+		return Stats{}
+	}
+	firstStep := max(from/a.Step, 0)
+	lastStep := min((to-1)/a.Step, len(a.peaks)-1)
+	var peak float32
+	for i := firstStep; i <= lastStep; i++ {
+		peak = max(peak, a.peaks[i])
+	}
+	// Only blocks that fit into the range completely
+	firstBlock := (from + a.Step - 1) / a.Step
+	lastBlock := min(to/a.Step-stepsPerBlock, len(a.blocks)-1)
+	var lufs float64 = math.Inf(-1)
+	if firstBlock <= lastBlock {
+		lufs = integrate(a.blocks[firstBlock : lastBlock+1])
+	}
+	return Stats{
+		init:     true,
+		LUFS:     lufs,
+		TruePeak: toDB(float64(peak)),
+	}
+}
+
+func integrate(blocks []float64) float64 {
+	var sum float64
+	var count int
+	for _, it := range blocks {
+		if blockLoudness(it) > absoluteGate {
+			sum += it
+			count++
+		}
+	}
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	gate := blockLoudness(sum/float64(count)) + relativeGate
+	sum, count = 0, 0
+	for _, it := range blocks {
+		l := blockLoudness(it)
+		if l > absoluteGate && l > gate {
+			sum += it
+			count++
+		}
+	}
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	return blockLoudness(sum / float64(count))
+}
+
+func blockLoudness(meanSquare float64) float64 {
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+func toDB(v float64) float64 {
+	return 20 * math.Log10(v)
+}
+
+// Scans samples for K-weighted block energy and 4x oversampled true peak.
+// Mono is measured as a single channel, as BS.1770 wants it, not as the same signal in both.
+// Returns an empty analysis and context error if it was canceled.
+func Analyze(ctx context.Context, samples audio.Samples, sampleRate, channels int) (Analysis, error) {
+	step := int(float64(sampleRate) * stepSec)
+	stepsN := samples.Len() / step
+	if step == 0 || stepsN == 0 {
+		return Analysis{}, nil
+	}
+	a := Analysis{
+		Step:  step,
+		peaks: make([]float32, stepsN),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.blocks = weighStepBlocks(ctx, samples, sampleRate, channels == 1, step, stepsN)
+	}()
+	workers := runtime.NumCPU()
+	chunk := (stepsN + workers - 1) / workers
+	for w := range workers {
+		from, to := w*chunk, min((w+1)*chunk, stepsN)
+		if from >= to {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scanTruePeaks(ctx, samples, a.peaks, step, from, to)
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return Analysis{}, err
+	}
+	a.Track = a.Measure(0, samples.Len())
+	return a, nil
+}
+
+// K-weighting is recursive, so this part is sequential
+func weighStepBlocks(ctx context.Context, samples audio.Samples, sampleRate int, isMono bool, step, stepsN int) []float64 {
+	left, right := newKFilter(sampleRate), newKFilter(sampleRate)
+	energy := make([]float64, stepsN)
+	for s := range stepsN {
+		if s%256 == 0 && ctx.Err() != nil {
+			return nil
+		}
+		var sum float64
+		for i := s * step; i < (s+1)*step; i++ {
+			l := left.process(float64(samples.Left(i)))
+			sum += l * l
+			if !isMono {
+				r := right.process(float64(samples.Right(i)))
+				sum += r * r
+			}
+		}
+		energy[s] = sum
+	}
+
+	blocks := make([]float64, max(stepsN-stepsPerBlock+1, 0))
+	blockLen := float64(step * stepsPerBlock)
+	for i := range blocks {
+		var sum float64
+		for _, it := range energy[i : i+stepsPerBlock] {
+			sum += it
+		}
+		blocks[i] = sum / blockLen
+	}
+	return blocks
+}
+
+func scanTruePeaks(ctx context.Context, samples audio.Samples, peaks []float32, step, from, to int) {
+	taps := newTruePeakTaps()
+	n := samples.Len()
+	for s := from; s < to; s++ {
+		if s%256 == 0 && ctx.Err() != nil {
+			return
+		}
+		var peak float64
+		for i := s * step; i < (s+1)*step; i++ {
+			// max(|M+S|, |M-S|) is |M|+|S|, so both channels are covered by mid and side
+			peak = max(peak, math.Abs(float64(samples.Mid[i]))+math.Abs(float64(samples.Side[i])))
+			if i-tpHalfLen+1 < 0 || i+tpHalfLen >= n {
+				continue
+			}
+			for p := range taps {
+				var mid, side float64
+				for k, t := range taps[p] {
+					idx := i + k - tpHalfLen + 1
+					mid += float64(samples.Mid[idx]) * t
+					side += float64(samples.Side[idx]) * t
+				}
+				peak = max(peak, math.Abs(mid)+math.Abs(side))
+			}
+		}
+		peaks[s] = float32(peak)
+	}
+}
+
+// Loudness of a track or a section, zero value means it wasn't measured
+type Stats struct {
+	init     bool
+	LUFS     float64
+	TruePeak float64 // dBTP
+}
+
+func (s Stats) IsMeasured() bool {
+	return s.init
+}
+
+func (s Stats) LUFSValueString() string {
+	if !s.init {
+		return ""
+	}
+	return formatDB(s.LUFS)
+}
+
+func (s Stats) LUFSString() string {
+	if !s.init {
+		return ""
+	}
+	return formatDB(s.LUFS) + " LUFS"
+}
+
+func (s Stats) TruePeakValueString() string {
+	if !s.init {
+		return ""
+	}
+	return formatDB(s.TruePeak)
+}
+
+func (s Stats) TruePeakString() string {
+	if !s.init {
+		return ""
+	}
+	return formatDB(s.TruePeak) + " dBTP"
+}
+
+func formatDB(v float64) string {
+	if math.IsInf(v, -1) {
+		return "-∞"
+	}
+	// Avoid "-0.0"
+	if v = math.Round(v*10) / 10; v == 0 {
+		v = 0
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+const (
+	TargetLUFS  = -16.0
+	PeakCeiling = -1.0 // dBTP
+)
+
+// Gain in dB that brings the track to TargetLUFS without pushing true peak over PeakCeiling
+func NormalisationGain(s Stats) float64 {
+	if !s.init || math.IsInf(s.LUFS, -1) {
+		return 0
+	}
+	gain := TargetLUFS - s.LUFS
+	if !math.IsInf(s.TruePeak, -1) {
+		gain = min(gain, PeakCeiling-s.TruePeak)
+	}
+	return math.Round(gain*10) / 10
+}
+
+func FormatGain(db float64) string {
+	return fmt.Sprintf("%+.1f dB", db)
+}
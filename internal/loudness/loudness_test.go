@@ -0,0 +1,96 @@
+package loudness
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/spyhere/re-peat/internal/audio"
+)
+
+const testRate = 48000
+
+// Left and right channels are turned into mid and side, the way the decoder keeps them
+func testSamples(left, right []float64) audio.Samples {
+	s := audio.Samples{Mid: make([]float32, len(left)), Side: make([]float32, len(left))}
+	for i := range left {
+		s.Mid[i] = float32((left[i] + right[i]) / 2)
+		s.Side[i] = float32((left[i] - right[i]) / 2)
+	}
+	return s
+}
+
+func sine(freq, amplitude, phase float64, seconds float64) []float64 {
+	out := make([]float64, int(seconds*testRate))
+	for i := range out {
+		out[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/testRate+phase)
+	}
+	return out
+}
+
+func analyze(t *testing.T, s audio.Samples, channels int) Stats {
+	t.Helper()
+	a, err := Analyze(context.Background(), s, testRate, channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.Track
+}
+
+func TestMonoIsOneChannel(t *testing.T) {
+	tone := sine(997, 1, 0, 10)
+	got := analyze(t, testSamples(tone, tone), 1)
+	if math.Abs(got.LUFS+3.01) > 0.05 {
+		t.Errorf("full scale mono sine: got %.2f LUFS, want -3.01", got.LUFS)
+	}
+}
+
+// BS.1770 reference: a full scale 997 Hz sine in one channel is -3.01 LUFS, in both channels it's 0
+func TestStereoSine(t *testing.T) {
+	tone, silence := sine(997, 1, 0, 10), make([]float64, 10*testRate)
+	if got := analyze(t, testSamples(tone, silence), 2); math.Abs(got.LUFS+3.01) > 0.05 {
+		t.Errorf("sine in the left channel: got %.2f LUFS, want -3.01", got.LUFS)
+	}
+	if got := analyze(t, testSamples(tone, tone), 2); math.Abs(got.LUFS) > 0.05 {
+		t.Errorf("sine in both channels: got %.2f LUFS, want 0", got.LUFS)
+	}
+}
+
+func TestGating(t *testing.T) {
+	tone := sine(997, 1, 0, 5)
+	withSilence := append(append([]float64{}, tone...), make([]float64, 5*testRate)...)
+	got := analyze(t, testSamples(withSilence, withSilence), 2)
+	// Without the absolute gate half of silence would take 3 dB off. Blocks across the edge still count
+	if math.Abs(got.LUFS) > 0.2 {
+		t.Errorf("sine followed by silence: got %.2f LUFS, want 0", got.LUFS)
+	}
+
+	quiet := sine(997, math.Pow(10, -30.0/20), 0, 5)
+	withQuiet := append(append([]float64{}, tone...), quiet...)
+	got = analyze(t, testSamples(withQuiet, withQuiet), 2)
+	// The quiet half is 30 dB down, below the relative gate
+	if math.Abs(got.LUFS) > 0.2 {
+		t.Errorf("sine followed by a quiet one: got %.2f LUFS, want 0", got.LUFS)
+	}
+
+	silence := make([]float64, 5*testRate)
+	if got = analyze(t, testSamples(silence, silence), 2); !math.IsInf(got.LUFS, -1) {
+		t.Errorf("silence: got %.2f LUFS, want -inf", got.LUFS)
+	}
+}
+
+// A quarter of the sample rate at 45° has every sample at 0.707, while the wave itself reaches 1
+func TestTruePeakBetweenSamples(t *testing.T) {
+	tone := sine(testRate/4, 1, math.Pi/4, 1)
+	var samplePeak float64
+	for _, it := range tone {
+		samplePeak = max(samplePeak, math.Abs(it))
+	}
+	if db := toDB(samplePeak); math.Abs(db+3.01) > 0.01 {
+		t.Fatalf("sample peak is %.2f dB, the test signal is wrong", db)
+	}
+	got := analyze(t, testSamples(tone, tone), 2)
+	if math.Abs(got.TruePeak) > 0.5 {
+		t.Errorf("got %.2f dBTP, want 0", got.TruePeak)
+	}
+}
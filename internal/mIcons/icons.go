@@ -38,4 +38,11 @@ var (
 	Folder           = newIcon(icons.FileFolder)
 	Save             = newIcon(icons.ContentSave)
 	Info             = newIcon(icons.ActionInfo)
+	Shift            = newIcon(icons.ActionSwapHoriz)
+	Compare          = newIcon(icons.ActionCompareArrows)
+	Merge            = newIcon(icons.EditorMergeType)
+	Difference       = newIcon(icons.ImageCompare)
+	Import           = newIcon(icons.FileFileDownload)
+	Export           = newIcon(icons.FileFileUpload)
+	Archive          = newIcon(icons.ContentArchive)
 )
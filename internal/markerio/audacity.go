@@ -0,0 +1,88 @@
+package markerio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+// Audacity label track: "start<TAB>end<TAB>label" per line, times in seconds.
+// Point labels have the same start and end, region labels become region markers.
+func EncodeAudacity(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	rate := doc.Audio.SampleRate
+	for _, it := range doc.Markers {
+		end := it.Samples
+		if it.IsRegion() {
+			end = it.End
+		}
+		label := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(it.Name)
+		_, err := fmt.Fprintf(bw, "%.6f\t%.6f\t%s\n", samplesToSeconds(it.Samples, rate), samplesToSeconds(end, rate), label)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func DecodeAudacity(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error) {
+	markers := tm.NewTimeMarkers()
+	var rowErrs []RowError
+	scanner := bufio.NewScanner(r)
+	row := 0
+	for scanner.Scan() {
+		row++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Frequency range of spectral labels is written on the following line starting with "\"
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "\\") {
+			continue
+		}
+		marker, err := parseAudacityLabel(line, t.Audio)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: row, Err: err})
+			continue
+		}
+		if !markers.AttachNewMarker(marker) {
+			rowErrs = append(rowErrs, RowError{Row: row, Err: ErrLimit})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, rowErrs, err
+	}
+	markers.Sort()
+	return markers, rowErrs, nil
+}
+
+func parseAudacityLabel(line string, a audio.AudioMeta) (tm.TimeMarker, error) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) < 2 {
+		return tm.TimeMarker{}, errors.New("expected start and end separated by tab")
+	}
+	start, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	if err != nil {
+		return tm.TimeMarker{}, fmt.Errorf("start: %w", err)
+	}
+	end, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	if err != nil {
+		return tm.TimeMarker{}, fmt.Errorf("end: %w", err)
+	}
+	if err := checkTime(start, a); err != nil {
+		return tm.TimeMarker{}, err
+	}
+	name := ""
+	if len(fields) == 3 {
+		name = strings.TrimSpace(fields[2])
+	}
+	startS := secondsToSamples(start, a.SampleRate)
+	endS := 0
+	if end > start {
+		endS = min(secondsToSamples(end, a.SampleRate), a.MaxMonoSamples())
+	}
+	return newMarker(startS, endS, name), nil
+}
@@ -0,0 +1,63 @@
+package markerio
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func testMeta() audio.AudioMeta {
+	return audio.NewAudioMeta(48000, 2, 48000*60)
+}
+
+func TestAudacityRoundTrip(t *testing.T) {
+	a := testMeta()
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(0, 0, "Intro"))
+	markers.AttachNewMarker(newMarker(48000*10+123, 48000*20, "Verse\tone"))
+	var buf bytes.Buffer
+	if err := EncodeAudacity(&buf, Document{Markers: markers, Target: Target{Audio: a}}); err != nil {
+		t.Fatal(err)
+	}
+	got, rowErrs, err := DecodeAudacity(&buf, Target{Audio: a})
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 markers, got %d", len(got))
+	}
+	if got[0].Name != "Intro" || got[0].IsRegion() {
+		t.Errorf("unexpected point marker %+v", got[0])
+	}
+	if got[1].Samples != 48000*10+123 || got[1].End != 48000*20 || got[1].Name != "Verse one" {
+		t.Errorf("unexpected region marker: %d..%d %q", got[1].Samples, got[1].End, got[1].Name)
+	}
+}
+
+func TestAudacityRowErrors(t *testing.T) {
+	input := strings.Join([]string{
+		"1.5\t1.5\tFirst",
+		"\\\t100.0\t2000.0", // frequency range of a spectral label
+		"oops\t2\tBroken",
+		"3600\t3600\tToo late",
+		"",
+		"2\t4",
+	}, "\n")
+	got, rowErrs, err := DecodeAudacity(strings.NewReader(input), Target{Audio: testMeta()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Name != "" || got[1].End != 48000*4 {
+		t.Fatalf("unexpected markers %d", len(got))
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Row != 3 || rowErrs[1].Row != 4 {
+		t.Fatalf("unexpected row errors %v", rowErrs)
+	}
+	if !errors.Is(rowErrs[1].Err, ErrOutOfTrack) {
+		t.Errorf("expected out of track error, got %v", rowErrs[1].Err)
+	}
+}
@@ -0,0 +1,316 @@
+package markerio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+// Fields of a marker that can be taken from a table column
+type Field int
+
+const (
+	FieldTime Field = iota
+	FieldSamples
+	FieldName
+	FieldTags
+	FieldNotes
+	fieldsCount
+)
+
+// Column index for every field, -1 if the field is not in the table
+type Mapping [fieldsCount]int
+
+var ErrNoTimeColumn = errors.New("neither time nor samples column is chosen")
+
+const tagsSeparator = ";"
+
+var csvHeader = []string{"#", "Time", "Samples", "Name", "Tags", "Notes"}
+
+func EncodeCSV(w io.Writer, doc Document) error {
+	return encodeTable(w, doc, ',')
+}
+
+func EncodeTSV(w io.Writer, doc Document) error {
+	return encodeTable(w, doc, '\t')
+}
+
+func encodeTable(w io.Writer, doc Document, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for idx, it := range doc.Markers {
+		err := cw.Write([]string{
+			strconv.Itoa(idx + 1),
+			formatTime(samplesToSeconds(it.Samples, doc.Audio.SampleRate)),
+			strconv.Itoa(it.Samples),
+			it.Name,
+			strings.Join(it.CategoryTags, tagsSeparator+" "),
+			it.Notes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Rows of a delimited file. Header is empty if the first row contains data
+type Table struct {
+	Header []string
+	Rows   [][]string
+	Width  int // the widest row
+}
+
+func ReadCSV(r io.Reader) (Table, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(4096)
+	return readTable(br, sniffComma(string(head)))
+}
+
+func ReadTSV(r io.Reader) (Table, error) {
+	return readTable(r, '\t')
+}
+
+// Spreadsheets in some locales separate values with semicolons
+func sniffComma(head string) rune {
+	line, _, _ := strings.Cut(head, "\n")
+	comma, count := ',', strings.Count(line, ",")
+	for _, it := range []rune{';', '\t'} {
+		if n := strings.Count(line, string(it)); n > count {
+			comma, count = it, n
+		}
+	}
+	return comma
+}
+
+func readTable(r io.Reader, comma rune) (Table, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	var t Table
+	for _, it := range rows {
+		t.Width = max(t.Width, len(it))
+	}
+	if len(rows) > 0 && isHeader(rows[0]) {
+		t.Header, rows = rows[0], rows[1:]
+	}
+	t.Rows = rows
+	return t, nil
+}
+
+// Row is a header if none of its cells is a number or a time
+func isHeader(row []string) bool {
+	for _, it := range row {
+		if _, err := parseTime(it); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Names of the columns to choose from, header cells or letters like in spreadsheets
+func (t Table) Columns() []string {
+	columns := make([]string, t.Width)
+	for idx := range columns {
+		if idx < len(t.Header) && strings.TrimSpace(t.Header[idx]) != "" {
+			columns[idx] = strings.TrimSpace(t.Header[idx])
+		} else {
+			columns[idx] = columnLetter(idx)
+		}
+	}
+	return columns
+}
+
+func columnLetter(idx int) string {
+	name := ""
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+	return name
+}
+
+var fieldAliases = [fieldsCount][]string{
+	FieldTime:    {"time", "start", "position", "timecode", "время", "начало"},
+	FieldSamples: {"samples", "sample", "сэмплы"},
+	FieldName:    {"name", "title", "label", "cue", "marker", "имя", "название"},
+	FieldTags:    {"tags", "tag", "category", "categories", "категории", "теги"},
+	FieldNotes:   {"notes", "note", "comment", "comments", "description", "заметки", "комментарий"},
+}
+
+// Mapping guessed from the header names. Without a header the column order of the export is assumed
+func (t Table) GuessMapping() Mapping {
+	var m Mapping
+	for idx := range m {
+		m[idx] = -1
+	}
+	if len(t.Header) == 0 {
+		// "#", "Time", "Samples", "Name", "Tags", "Notes"
+		for field, column := range [fieldsCount]int{1, 2, 3, 4, 5} {
+			if column < t.Width {
+				m[field] = column
+			}
+		}
+		return m
+	}
+	for column, it := range t.Header {
+		name := strings.ToLower(strings.TrimSpace(it))
+		for field, aliases := range fieldAliases {
+			for _, alias := range aliases {
+				if m[field] == -1 && strings.HasPrefix(name, alias) {
+					m[field] = column
+				}
+			}
+		}
+	}
+	return m
+}
+
+// Converts rows into markers. Rows which can't be converted are reported and skipped
+func (t Table) Decode(m Mapping, a audio.AudioMeta) (tm.TimeMarkers, []RowError, error) {
+	if m[FieldTime] == -1 && m[FieldSamples] == -1 {
+		return nil, nil, ErrNoTimeColumn
+	}
+	firstRow := 1
+	if len(t.Header) > 0 {
+		firstRow = 2
+	}
+	markers := tm.NewTimeMarkers()
+	var rowErrs []RowError
+	for idx, row := range t.Rows {
+		if isEmptyRow(row) {
+			continue
+		}
+		marker, err := decodeRow(row, m, a)
+		if err == nil && !markers.AttachNewMarker(marker) {
+			err = ErrLimit
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: firstRow + idx, Err: err})
+		}
+	}
+	markers.Sort()
+	return markers, rowErrs, nil
+}
+
+func isEmptyRow(row []string) bool {
+	for _, it := range row {
+		if strings.TrimSpace(it) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeRow(row []string, m Mapping, a audio.AudioMeta) (tm.TimeMarker, error) {
+	cell := func(f Field) string {
+		if m[f] < 0 || m[f] >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[m[f]])
+	}
+	samples, err := rowSamples(cell(FieldTime), cell(FieldSamples), a)
+	if err != nil {
+		return tm.TimeMarker{}, err
+	}
+	marker := newMarker(samples, 0, cell(FieldName))
+	marker.Notes = cell(FieldNotes)
+	for _, tag := range strings.Split(cell(FieldTags), tagsSeparator) {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && len(marker.CategoryTags) < tm.TagsLimit && !slices.Contains(marker.CategoryTags, tag) {
+			marker.CategoryTags = append(marker.CategoryTags, tag)
+		}
+	}
+	return marker, nil
+}
+
+// Time is exported rounded to milliseconds, samples within that rounding keep the exact position
+const timePrecision = 0.0005
+
+// Time wins, so it can be edited in a spreadsheet. Samples are used when time is empty,
+// or when they match the time, since they are more precise
+func rowSamples(timeCell, samplesCell string, a audio.AudioMeta) (int, error) {
+	samples := -1
+	var samplesErr error
+	if samplesCell != "" {
+		s, err := strconv.Atoi(samplesCell)
+		switch {
+		case err != nil:
+			samplesErr = fmt.Errorf("samples: %w", err)
+		case s < 0 || s > a.MaxMonoSamples():
+			samplesErr = fmt.Errorf("%w: %d samples", ErrOutOfTrack, s)
+		default:
+			samples = s
+		}
+	}
+	if timeCell == "" && samplesCell != "" {
+		return samples, samplesErr
+	}
+	seconds, err := parseTime(timeCell)
+	if err != nil {
+		return 0, fmt.Errorf("time: %w", err)
+	}
+	if err := checkTime(seconds, a); err != nil {
+		return 0, err
+	}
+	if samples >= 0 && math.Abs(samplesToSeconds(samples, a.SampleRate)-seconds) <= timePrecision {
+		return samples, nil
+	}
+	return secondsToSamples(seconds, a.SampleRate), nil
+}
+
+// "h:mm:ss.fff" with hours omitted when zero
+func formatTime(seconds float64) string {
+	ms := int(math.Round(seconds * 1000))
+	h, m, s, ms := ms/3600000, ms/60000%60, ms/1000%60, ms%1000
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%03d", h, m, s, ms)
+	}
+	return fmt.Sprintf("%02d:%02d.%03d", m, s, ms)
+}
+
+// Accepts plain seconds ("75.5") and clock time ("1:15.5", "0:01:15")
+func parseTime(v string) (float64, error) {
+	v = strings.ReplaceAll(strings.TrimSpace(v), ",", ".")
+	if v == "" {
+		return 0, errors.New("empty")
+	}
+	parts := strings.Split(v, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q", v)
+	}
+	seconds := 0.0
+	for idx, it := range parts {
+		var n float64
+		var err error
+		if idx == len(parts)-1 {
+			n, err = strconv.ParseFloat(it, 64)
+		} else {
+			var whole int
+			whole, err = strconv.Atoi(it)
+			n = float64(whole)
+		}
+		if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+			return 0, fmt.Errorf("invalid time %q", v)
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, nil
+}
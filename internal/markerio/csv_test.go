@@ -0,0 +1,126 @@
+package markerio
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	a := testMeta()
+	markers := tm.NewTimeMarkers()
+	first := newMarker(48000*15+24, 0, "Intro, soft")
+	first.CategoryTags = append(first.CategoryTags, "Music", "Cue")
+	first.Notes = "Lights \"down\"\nslowly"
+	markers.AttachNewMarker(first)
+	markers.AttachNewMarker(newMarker(48000*20, 0, "Verse"))
+	for _, it := range []struct {
+		encode func(*bytes.Buffer, Document) error
+		read   func(*bytes.Buffer) (Table, error)
+	}{
+		{func(b *bytes.Buffer, d Document) error { return EncodeCSV(b, d) }, func(b *bytes.Buffer) (Table, error) { return ReadCSV(b) }},
+		{func(b *bytes.Buffer, d Document) error { return EncodeTSV(b, d) }, func(b *bytes.Buffer) (Table, error) { return ReadTSV(b) }},
+	} {
+		var buf bytes.Buffer
+		if err := it.encode(&buf, Document{Markers: markers, Target: Target{Audio: a}}); err != nil {
+			t.Fatal(err)
+		}
+		table, err := it.read(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, rowErrs, err := table.Decode(table.GuessMapping(), a)
+		if err != nil || len(rowErrs) > 0 {
+			t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+		}
+		if len(got) != 2 || got[0].Samples != first.Samples || got[0].Name != first.Name || got[0].Notes != first.Notes {
+			t.Fatalf("unexpected markers %+v", got[0])
+		}
+		if !slices.Equal(got[0].CategoryTags, first.CategoryTags) {
+			t.Errorf("expected tags %v, got %v", first.CategoryTags, got[0].CategoryTags)
+		}
+	}
+}
+
+func TestCSVMappingAndRowErrors(t *testing.T) {
+	input := strings.Join([]string{
+		"Cue;Comment;Start",
+		"Opening;;0:05",
+		"Broken;;soon",
+		"Late;;2:00",
+		";;",
+		"Seconds;fast;12,5",
+	}, "\n")
+	table, err := ReadCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := table.GuessMapping()
+	if m[FieldName] != 0 || m[FieldNotes] != 1 || m[FieldTime] != 2 || m[FieldSamples] != -1 {
+		t.Fatalf("unexpected mapping %v", m)
+	}
+	got, rowErrs, err := table.Decode(m, testMeta())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Samples != 48000*5 || got[1].Samples != 48000*12+24000 || got[1].Notes != "fast" {
+		t.Fatalf("unexpected markers %d", len(got))
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Row != 3 || rowErrs[1].Row != 4 || !errors.Is(rowErrs[1].Err, ErrOutOfTrack) {
+		t.Errorf("unexpected row errors %v", rowErrs)
+	}
+
+	m[FieldTime] = -1
+	if _, _, err := table.Decode(m, testMeta()); !errors.Is(err, ErrNoTimeColumn) {
+		t.Errorf("expected ErrNoTimeColumn, got %v", err)
+	}
+}
+
+func TestCSVWithoutHeader(t *testing.T) {
+	table, err := ReadCSV(strings.NewReader("1,00:01.500,,First\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Header) != 0 || table.Columns()[3] != "D" {
+		t.Fatalf("unexpected table %+v", table)
+	}
+	got, _, err := table.Decode(table.GuessMapping(), testMeta())
+	if err != nil || len(got) != 1 || got[0].Samples != 72000 || got[0].Name != "First" {
+		t.Fatalf("unexpected result %v %v", got, err)
+	}
+}
+
+func TestCSVTimeOverSamples(t *testing.T) {
+	input := strings.Join([]string{
+		"Name,Time,Samples",
+		"Exact,00:01.000,48010",
+		"Edited,00:03.000,48010",
+		"No time,,96000",
+		"Bad samples,00:04.000,many",
+		"Bad time,later,48000",
+	}, "\n")
+	table, err := ReadCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, rowErrs, err := table.Decode(table.GuessMapping(), testMeta())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"Exact": 48010, "Edited": 48000 * 3, "No time": 96000, "Bad samples": 48000 * 4}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected markers %d", len(got))
+	}
+	for _, it := range got {
+		if it.Samples != want[it.Name] {
+			t.Errorf("%q: expected %d samples, got %d", it.Name, want[it.Name], it.Samples)
+		}
+	}
+	if len(rowErrs) != 1 || rowErrs[0].Row != 6 {
+		t.Errorf("unexpected row errors %v", rowErrs)
+	}
+}
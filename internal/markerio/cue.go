@@ -0,0 +1,279 @@
+package markerio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+const (
+	cueFPS       = 75 // CD frames per second
+	cueMaxTracks = 99
+	cueMaxText   = 80 // CD-Text limit for TITLE and PERFORMER
+)
+
+// CUE sheet with a track for every marker, INDEX 01 is the marker time rounded to CD frames
+func EncodeCUE(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "REM COMMENT \"re-peat\"\n")
+	fmt.Fprintf(bw, "FILE \"%s\" %s\n", cueText(doc.AudioName), cueFileType(doc.AudioName))
+	for idx, it := range doc.Markers[:min(len(doc.Markers), cueMaxTracks)] {
+		fmt.Fprintf(bw, "  TRACK %02d AUDIO\n", idx+1)
+		if it.Name != "" {
+			fmt.Fprintf(bw, "    TITLE \"%s\"\n", cueText(it.Name))
+		}
+		fmt.Fprintf(bw, "    INDEX 01 %s\n", formatCueTime(cueFrames(it.Samples, doc.Audio.SampleRate)))
+	}
+	return bw.Flush()
+}
+
+func CheckCUE(doc Document) []Issue {
+	var issues []Issue
+	for idx, it := range doc.Markers {
+		if idx >= cueMaxTracks {
+			issues = append(issues, Issue{Marker: idx, Kind: IssueDropped})
+			continue
+		}
+		rate := doc.Audio.SampleRate
+		rounded := float64(cueFrames(it.Samples, rate)) / cueFPS
+		if shift := rounded - samplesToSeconds(it.Samples, rate); math.Abs(shift) > 0.5/float64(rate) {
+			issues = append(issues, Issue{Marker: idx, Kind: IssueRounded, Shift: shift})
+		}
+		if cueReplaced(it.Name) != it.Name {
+			issues = append(issues, Issue{Marker: idx, Kind: IssueReplaced})
+		}
+		if utf8.RuneCountInString(it.Name) > cueMaxText {
+			issues = append(issues, Issue{Marker: idx, Kind: IssueTruncated})
+		}
+		if !isASCII(it.Name) {
+			issues = append(issues, Issue{Marker: idx, Kind: IssueNonASCII})
+		}
+	}
+	return issues
+}
+
+func cueFrames(samples, sampleRate int) int {
+	return int(math.Round(float64(samples) * cueFPS / float64(sampleRate)))
+}
+
+func formatCueTime(frames int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", frames/cueFPS/60, frames/cueFPS%60, frames%cueFPS)
+}
+
+// Text in quotes can't have quotes or line breaks, CD-Text is limited in length
+func cueText(s string) string {
+	s = cueReplaced(s)
+	if utf8.RuneCountInString(s) > cueMaxText {
+		s = string([]rune(s)[:cueMaxText])
+	}
+	return s
+}
+
+func cueReplaced(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '"' {
+			return '\''
+		}
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// CD-Text has no UTF-8, burners and players show anything beyond ASCII in their own way
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func cueFileType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".mp3":
+		return "MP3"
+	case ".aif", ".aiff", ".aifc":
+		return "AIFF"
+	default:
+		return "WAVE"
+	}
+}
+
+type cueTrack struct {
+	row     int
+	number  int
+	title   string
+	indexes []cueIndex
+	file    string
+}
+
+type cueIndex struct {
+	row    int
+	number int
+	frames int
+}
+
+// Every INDEX 01 becomes a marker named after the track TITLE, further indexes get their number appended.
+// Tracks of other files in the sheet are reported, because their times don't belong to the loaded audio
+func DecodeCUE(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error) {
+	tracks, rowErrs, err := parseCUE(r)
+	if err != nil {
+		return nil, rowErrs, err
+	}
+	file := matchingCueFile(tracks, t.AudioName)
+	markers := tm.NewTimeMarkers()
+	for _, track := range tracks {
+		if track.file != file {
+			rowErrs = append(rowErrs, RowError{Row: track.row, Err: fmt.Errorf("track %02d belongs to \"%s\"", track.number, track.file)})
+			continue
+		}
+		for _, idx := range track.indexes {
+			if idx.number == 0 {
+				continue
+			}
+			name := track.title
+			if name == "" {
+				name = fmt.Sprintf("Track %02d", track.number)
+			}
+			if idx.number > 1 {
+				name = fmt.Sprintf("%s (%d)", name, idx.number)
+			}
+			seconds := float64(idx.frames) / cueFPS
+			if err := checkTime(seconds, t.Audio); err != nil {
+				rowErrs = append(rowErrs, RowError{Row: idx.row, Err: err})
+				continue
+			}
+			if !markers.AttachNewMarker(newMarker(secondsToSamples(seconds, t.Audio.SampleRate), 0, name)) {
+				rowErrs = append(rowErrs, RowError{Row: idx.row, Err: ErrLimit})
+			}
+		}
+	}
+	markers.Sort()
+	slices.SortStableFunc(rowErrs, func(a, b RowError) int { return a.Row - b.Row })
+	return markers, rowErrs, nil
+}
+
+// File of the sheet with the same name as the loaded audio, or the first one
+func matchingCueFile(tracks []cueTrack, audioName string) string {
+	for _, it := range tracks {
+		if strings.EqualFold(filepath.Base(it.file), audioName) {
+			return it.file
+		}
+	}
+	if len(tracks) > 0 {
+		return tracks[0].file
+	}
+	return ""
+}
+
+func parseCUE(r io.Reader) ([]cueTrack, []RowError, error) {
+	var tracks []cueTrack
+	var rowErrs []RowError
+	file := ""
+	scanner := bufio.NewScanner(r)
+	row := 0
+	for scanner.Scan() {
+		row++
+		line := strings.TrimSpace(scanner.Text())
+		if row == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		command, args, _ := strings.Cut(line, " ")
+		args = strings.TrimSpace(args)
+		var track *cueTrack
+		if len(tracks) > 0 {
+			track = &tracks[len(tracks)-1]
+		}
+		switch strings.ToUpper(command) {
+		case "FILE":
+			file = cueFileName(args)
+		case "TRACK":
+			number, _, _ := strings.Cut(args, " ")
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				rowErrs = append(rowErrs, RowError{Row: row, Err: fmt.Errorf("track number: %w", err)})
+				n = len(tracks) + 1
+			}
+			tracks = append(tracks, cueTrack{row: row, number: n, file: file})
+		case "TITLE":
+			if track != nil {
+				track.title = unquote(args)
+			}
+		case "INDEX":
+			if track == nil {
+				rowErrs = append(rowErrs, RowError{Row: row, Err: errors.New("INDEX outside of a TRACK")})
+				continue
+			}
+			idx, err := parseCueIndex(args)
+			if err != nil {
+				rowErrs = append(rowErrs, RowError{Row: row, Err: err})
+				continue
+			}
+			idx.row = row
+			track.indexes = append(track.indexes, idx)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, rowErrs, err
+	}
+	return tracks, rowErrs, nil
+}
+
+// FILE "name with spaces.wav" WAVE
+func cueFileName(args string) string {
+	if strings.HasPrefix(args, "\"") {
+		if end := strings.Index(args[1:], "\""); end >= 0 {
+			return args[1 : end+1]
+		}
+	}
+	if idx := strings.LastIndex(args, " "); idx > 0 {
+		return args[:idx]
+	}
+	return args
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// "01 mm:ss:ff"
+func parseCueIndex(args string) (cueIndex, error) {
+	number, stamp, ok := strings.Cut(args, " ")
+	if !ok {
+		return cueIndex{}, errors.New("INDEX needs a number and a time")
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return cueIndex{}, fmt.Errorf("index number: %w", err)
+	}
+	parts := strings.Split(strings.TrimSpace(stamp), ":")
+	if len(parts) != 3 {
+		return cueIndex{}, fmt.Errorf("invalid index time %q", stamp)
+	}
+	var values [3]int
+	for idx, it := range parts {
+		if values[idx], err = strconv.Atoi(it); err != nil || values[idx] < 0 {
+			return cueIndex{}, fmt.Errorf("invalid index time %q", stamp)
+		}
+	}
+	if values[1] >= 60 || values[2] >= cueFPS {
+		return cueIndex{}, fmt.Errorf("invalid index time %q", stamp)
+	}
+	return cueIndex{number: n, frames: (values[0]*60+values[1])*cueFPS + values[2]}, nil
+}
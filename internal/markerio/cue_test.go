@@ -0,0 +1,86 @@
+package markerio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func TestCUERoundTrip(t *testing.T) {
+	target := Target{Audio: testMeta(), AudioName: "show.wav"}
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(0, 0, "Overture"))
+	markers.AttachNewMarker(newMarker(48000*61+700, 0, "Say \"hi\"\nnow"))
+	doc := Document{Markers: markers, Target: target}
+
+	var buf bytes.Buffer
+	if err := EncodeCUE(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "FILE \"show.wav\" WAVE") || !strings.Contains(buf.String(), "INDEX 01 01:01:01") {
+		t.Fatalf("unexpected sheet:\n%s", buf.String())
+	}
+	issues := CheckCUE(doc)
+	if len(issues) != 2 || issues[0].Kind != IssueRounded || issues[1].Kind != IssueReplaced || issues[0].Marker != 1 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if shift := issues[0].Shift; shift >= 0 || shift < -0.5/cueFPS {
+		t.Errorf("unexpected shift %f", shift)
+	}
+
+	target.Audio.Seconds = 120
+	got, rowErrs, err := DecodeCUE(&buf, target)
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors %v %v", err, rowErrs)
+	}
+	if len(got) != 2 || got[1].Name != "Say 'hi' now" || got[1].Samples != 48000*61+640 {
+		t.Fatalf("unexpected markers %q %d", got[1].Name, got[1].Samples)
+	}
+}
+
+func TestCUEOtherFilesAndIndexes(t *testing.T) {
+	sheet := strings.Join([]string{
+		"\ufeffPERFORMER \"Band\"",
+		"FILE \"other.flac\" WAVE",
+		"  TRACK 01 AUDIO",
+		"    INDEX 01 00:00:00",
+		"FILE \"Show.WAV\" WAVE",
+		"  TRACK 02 AUDIO",
+		"    TITLE \"Act one\"",
+		"    INDEX 00 00:01:00",
+		"    INDEX 01 00:02:00",
+		"    INDEX 02 00:10:74",
+		"  TRACK 03 AUDIO",
+		"    INDEX 01 00:20:99",
+	}, "\n")
+	got, rowErrs, err := DecodeCUE(strings.NewReader(sheet), Target{Audio: testMeta(), AudioName: "show.wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "Act one" || got[0].Samples != 96000 || got[1].Name != "Act one (2)" {
+		t.Fatalf("unexpected markers %d", len(got))
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Row != 3 || rowErrs[1].Row != 12 {
+		t.Errorf("unexpected row errors %v", rowErrs)
+	}
+}
+
+func TestCheckCUENames(t *testing.T) {
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(0, 0, strings.Repeat("a", cueMaxText+1)))
+	markers.AttachNewMarker(newMarker(48000, 0, "Припев"))
+	markers.AttachNewMarker(newMarker(48000*2, 0, "Coda"))
+	issues := CheckCUE(Document{Markers: markers, Target: Target{Audio: testMeta()}})
+	// Only the length of the first name is a problem, there is nothing to replace in it
+	want := []Issue{{Marker: 0, Kind: IssueTruncated}, {Marker: 1, Kind: IssueNonASCII}}
+	if len(issues) != len(want) {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	for idx := range want {
+		if issues[idx] != want[idx] {
+			t.Errorf("issue %d: got %+v, want %+v", idx, issues[idx], want[idx])
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package markerio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func testEmbeddedMarkers() tm.TimeMarkers {
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(0, 0, "Intro"))
+	region := newMarker(48000*10+7, 48000*12, "Verse")
+	region.Notes = "slow down"
+	markers.AttachNewMarker(region)
+	return markers
+}
+
+// RIFF with "fmt ", an odd sized "data" chunk and a "cue " chunk to be replaced
+func writeTestWAV(t *testing.T) string {
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	chunk := func(id string, data []byte) {
+		body.WriteString(id)
+		binary.Write(&body, binary.LittleEndian, uint32(len(data)))
+		body.Write(data)
+		if len(data)&1 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	chunk("fmt ", make([]byte, 16))
+	chunk("data", []byte{1, 2, 3})
+	chunk("cue ", encodeCuePoints(tm.TimeMarkers{&tm.TimeMarker{Samples: 5}}))
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	binary.Write(&file, binary.LittleEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+	path := filepath.Join(t.TempDir(), "take.wav")
+	if err := os.WriteFile(path, file.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	path := writeTestWAV(t)
+	target := Target{Audio: testMeta(), AudioName: "take.wav", AudioPath: path}
+	var buf bytes.Buffer
+	if err := EncodeWAV(&buf, Document{Markers: testEmbeddedMarkers(), Target: target}); err != nil {
+		t.Fatal(err)
+	}
+	if size := binary.LittleEndian.Uint32(buf.Bytes()[4:]); int(size) != buf.Len()-8 {
+		t.Fatalf("RIFF size %d doesn't match file length %d", size, buf.Len())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte{'d', 'a', 't', 'a', 3, 0, 0, 0, 1, 2, 3, 0}) {
+		t.Fatal("audio is not copied")
+	}
+	got, rowErrs, err := DecodeWAV(&buf, target)
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 markers, got %d", len(got))
+	}
+	if got[0].Name != "Intro" || got[0].IsRegion() {
+		t.Errorf("unexpected point marker %+v", got[0])
+	}
+	if got[1].Samples != 48000*10+7 || got[1].End != 48000*12 || got[1].Notes != "slow down" {
+		t.Errorf("unexpected region marker %+v", got[1])
+	}
+}
+
+func TestWAVChunkLongerThanFile(t *testing.T) {
+	for _, id := range []string{"LIST", "data"} {
+		var file bytes.Buffer
+		file.WriteString("RIFF\x00\x00\x00\x00WAVE")
+		file.WriteString(id)
+		binary.Write(&file, binary.LittleEndian, uint32(0xfffffff0))
+		file.WriteString("adtl")
+		path := filepath.Join(t.TempDir(), "broken.wav")
+		if err := os.WriteFile(path, file.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := ReadEmbedded(path, Target{Audio: testMeta()}); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%q: expected unexpected EOF, got %v", id, err)
+		}
+		doc := Document{Markers: testEmbeddedMarkers(), Target: Target{Audio: testMeta(), AudioPath: path}}
+		if err := EncodeWAV(io.Discard, doc); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%q: expected unexpected EOF on export, got %v", id, err)
+		}
+	}
+}
+
+func writeTestFLAC(t *testing.T, comments []string) string {
+	streamInfo := make([]byte, 34)
+	binary.BigEndian.PutUint64(streamInfo[10:], 48000<<44|1<<41|15<<36|48000*60)
+	var file bytes.Buffer
+	file.WriteString("fLaC")
+	file.Write([]byte{flacStreamInfo, 0, 0, 34})
+	file.Write(streamInfo)
+	vorbis := encodeVorbisComment("test", comments)
+	file.Write([]byte{flacVorbisComment | flacLastBlock, 0, byte(len(vorbis) >> 8), byte(len(vorbis))})
+	file.Write(vorbis)
+	file.Write([]byte{0xff, 0xf8, 1, 2})
+	path := filepath.Join(t.TempDir(), "take.flac")
+	if err := os.WriteFile(path, file.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFLACRoundTrip(t *testing.T) {
+	path := writeTestFLAC(t, []string{"ARTIST=Band", "CHAPTER001=00:00:01.000", "CUESHEET=stale"})
+	target := Target{Audio: testMeta(), AudioName: "take.flac", AudioPath: path}
+	var buf bytes.Buffer
+	if err := EncodeFLAC(&buf, Document{Markers: testEmbeddedMarkers(), Target: target}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte{0xff, 0xf8, 1, 2}) {
+		t.Fatal("audio frames are not copied")
+	}
+	blocks, err := readFLACBlocks(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 3 || blocks[0].kind != flacStreamInfo {
+		t.Fatalf("unexpected blocks %d", len(blocks))
+	}
+	_, comments := parseVorbisComment(blocks[1].data)
+	want := []string{"ARTIST=Band", "CHAPTER001=00:00:00.000", "CHAPTER001NAME=Intro", "CHAPTER002=00:00:10.000", "CHAPTER002NAME=Verse"}
+	if len(comments) != len(want) {
+		t.Fatalf("unexpected comments %q", comments)
+	}
+	for idx := range want {
+		if comments[idx] != want[idx] {
+			t.Errorf("comment %d: expected %q, got %q", idx, want[idx], comments[idx])
+		}
+	}
+
+	got, rowErrs, err := DecodeFLAC(&buf, target)
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	// Positions come from the cue sheet, since chapters are rounded to milliseconds
+	if len(got) != 2 || got[1].Samples != 48000*10+7 || got[1].Name != "Verse" {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+}
+
+func TestFLACChapters(t *testing.T) {
+	path := writeTestFLAC(t, []string{
+		"CHAPTER01=00:00:02.500", "CHAPTER01NAME=Chorus",
+		"CHAPTER02NAME=No time",
+		"CHAPTER03=later",
+	})
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	got, rowErrs, err := DecodeFLAC(file, Target{Audio: testMeta()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Samples != 48000*2.5 || got[0].Name != "Chorus" {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Row != 2 || rowErrs[1].Row != 3 {
+		t.Fatalf("unexpected row errors %v", rowErrs)
+	}
+}
+
+func TestEmbeddedByContent(t *testing.T) {
+	exporterNames := func(t Target) []string {
+		var names []string
+		for _, it := range ExportersFor(t) {
+			if it.Supports != nil {
+				names = append(names, it.Name)
+			}
+		}
+		return names
+	}
+	renamed := func(path, name string) string {
+		to := filepath.Join(filepath.Dir(path), name)
+		if err := os.Rename(path, to); err != nil {
+			t.Fatal(err)
+		}
+		return to
+	}
+	wavPath := renamed(writeTestWAV(t), "take.flac")
+	flacPath := renamed(writeTestFLAC(t, []string{"CHAPTER01=00:00:02.500", "CHAPTER01NAME=Chorus"}), "take.wav")
+
+	if names := exporterNames(Target{AudioPath: wavPath}); len(names) != 1 || names[0] != "WAV" {
+		t.Errorf("WAV named .flac: unexpected exporters %q", names)
+	}
+	if names := exporterNames(Target{AudioPath: flacPath}); len(names) != 1 || names[0] != "FLAC" {
+		t.Errorf("FLAC named .wav: unexpected exporters %q", names)
+	}
+	got, _, err := ReadEmbedded(flacPath, Target{Audio: testMeta()})
+	if err != nil || len(got) != 1 || got[0].Name != "Chorus" {
+		t.Errorf("FLAC named .wav: unexpected markers %+v, %v", got, err)
+	}
+	if got, _, err = ReadEmbedded(wavPath, Target{Audio: testMeta()}); err != nil || len(got) != 1 {
+		t.Errorf("WAV named .flac: unexpected markers %+v, %v", got, err)
+	}
+}
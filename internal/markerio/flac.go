@@ -0,0 +1,335 @@
+package markerio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+var ErrNotFLAC = errors.New("not a FLAC file")
+
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+	flacCueSheet      = 5
+
+	flacLastBlock      = 0x80
+	flacLeadOut        = 255 // lead-out track number of a non-CD cue sheet
+	flacCueSheetHead   = 128 + 8 + 259 + 1
+	flacCueSheetTrack  = 8 + 1 + 12 + 14 + 1
+	flacCueSheetIndex  = 8 + 1 + 3
+	flacMaxBlockLength = 1<<24 - 1
+)
+
+type flacBlock struct {
+	kind byte
+	data []byte
+}
+
+// Vorbis chapter extension: CHAPTER001=00:01:15.500 and CHAPTER001NAME=Verse
+var chapterKey = regexp.MustCompile(`^CHAPTER(\d+)(NAME)?$`)
+
+// Markers from CHAPTERxxx comments, CUESHEET comment or CUESHEET block, whichever is found first.
+// Chapters take exact positions from the CUESHEET block when both describe the same number of markers
+func DecodeFLAC(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error) {
+	blocks, err := readFLACBlocks(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var comments []string
+	var cueSheet []int
+	for _, it := range blocks {
+		switch it.kind {
+		case flacVorbisComment:
+			_, comments = parseVorbisComment(it.data)
+		case flacCueSheet:
+			cueSheet = parseCueSheetBlock(it.data)
+		}
+	}
+	chapters, rowErrs := parseChapters(comments)
+	if len(chapters) == 0 {
+		for _, it := range comments {
+			if key, value, _ := strings.Cut(it, "="); strings.EqualFold(key, "CUESHEET") {
+				return DecodeCUE(strings.NewReader(value), t)
+			}
+		}
+	}
+	exact := len(cueSheet) > 0 && len(cueSheet) == len(chapters)
+	if len(chapters) == 0 {
+		for idx := range cueSheet {
+			chapters = append(chapters, chapter{row: idx + 1, name: fmt.Sprintf("Track %02d", idx+1)})
+		}
+		exact = true
+	}
+
+	markers := tm.NewTimeMarkers()
+	for idx, it := range chapters {
+		samples := secondsToSamples(it.seconds, t.Audio.SampleRate)
+		if exact {
+			samples = cueSheet[idx]
+		}
+		if samples > t.Audio.MaxMonoSamples() {
+			rowErrs = append(rowErrs, RowError{Row: it.row, Err: fmt.Errorf("%w: %d samples", ErrOutOfTrack, samples)})
+			continue
+		}
+		if !markers.AttachNewMarker(newMarker(samples, 0, it.name)) {
+			rowErrs = append(rowErrs, RowError{Row: it.row, Err: ErrLimit})
+		}
+	}
+	markers.Sort()
+	slices.SortStableFunc(rowErrs, func(a, b RowError) int { return a.Row - b.Row })
+	return markers, rowErrs, nil
+}
+
+func readFLACBlocks(r io.Reader) ([]flacBlock, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || string(magic[:]) != "fLaC" {
+		return nil, ErrNotFLAC
+	}
+	var blocks []flacBlock
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, fmt.Errorf("metadata block: %w", err)
+		}
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		block := flacBlock{kind: header[0] &^ flacLastBlock, data: make([]byte, length)}
+		if _, err := io.ReadFull(r, block.data); err != nil {
+			return nil, fmt.Errorf("metadata block %d: %w", block.kind, err)
+		}
+		blocks = append(blocks, block)
+		if header[0]&flacLastBlock != 0 {
+			return blocks, nil
+		}
+	}
+}
+
+func parseVorbisComment(data []byte) (vendor string, comments []string) {
+	next := func() (string, bool) {
+		if len(data) < 4 {
+			return "", false
+		}
+		length := int(binary.LittleEndian.Uint32(data))
+		if length > len(data)-4 {
+			return "", false
+		}
+		s := string(data[4 : 4+length])
+		data = data[4+length:]
+		return s, true
+	}
+	vendor, ok := next()
+	if !ok || len(data) < 4 {
+		return vendor, nil
+	}
+	count := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+	for range count {
+		s, ok := next()
+		if !ok {
+			break
+		}
+		comments = append(comments, s)
+	}
+	return vendor, comments
+}
+
+// Positions of INDEX 01 of every track except lead-out
+func parseCueSheetBlock(data []byte) []int {
+	if len(data) < flacCueSheetHead {
+		return nil
+	}
+	count := int(data[flacCueSheetHead-1])
+	data = data[flacCueSheetHead:]
+	var offsets []int
+	for range count {
+		if len(data) < flacCueSheetTrack {
+			break
+		}
+		offset := binary.BigEndian.Uint64(data)
+		number := data[8]
+		indexes := int(data[flacCueSheetTrack-1])
+		data = data[flacCueSheetTrack:]
+		for range indexes {
+			if len(data) < flacCueSheetIndex {
+				return offsets
+			}
+			if number != flacLeadOut && data[8] == 1 {
+				offsets = append(offsets, int(offset+binary.BigEndian.Uint64(data)))
+			}
+			data = data[flacCueSheetIndex:]
+		}
+	}
+	return offsets
+}
+
+type chapter struct {
+	row     int // number of the chapter
+	seconds float64
+	name    string
+	timed   bool
+	invalid bool
+}
+
+func parseChapters(comments []string) ([]chapter, []RowError) {
+	byNumber := map[int]*chapter{}
+	var rowErrs []RowError
+	for _, it := range comments {
+		key, value, _ := strings.Cut(it, "=")
+		match := chapterKey.FindStringSubmatch(strings.ToUpper(key))
+		if match == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(match[1])
+		c, ok := byNumber[number]
+		if !ok {
+			c = &chapter{row: number}
+			byNumber[number] = c
+		}
+		if match[2] != "" {
+			c.name = value
+			continue
+		}
+		seconds, err := parseTime(value)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: number, Err: fmt.Errorf("time: %w", err)})
+			c.invalid = true
+			continue
+		}
+		c.seconds, c.timed = seconds, true
+	}
+	chapters := make([]chapter, 0, len(byNumber))
+	for _, it := range byNumber {
+		switch {
+		case it.invalid:
+		case !it.timed:
+			rowErrs = append(rowErrs, RowError{Row: it.row, Err: errors.New("chapter has no time")})
+		default:
+			chapters = append(chapters, *it)
+		}
+	}
+	slices.SortFunc(chapters, func(a, b chapter) int { return a.row - b.row })
+	return chapters, rowErrs
+}
+
+// Copy of the loaded FLAC with markers as CHAPTERxxx comments and a CUESHEET block with exact positions.
+// Chapters carry names only, notes, tags and region ends are not written
+func EncodeFLAC(w io.Writer, doc Document) error {
+	src, err := os.Open(doc.AudioPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// Not buffered, the file position is where audio frames start afterwards
+	blocks, err := readFLACBlocks(src)
+	if err != nil {
+		return err
+	}
+
+	totalSamples := uint64(doc.Audio.MonoSamplesLen)
+	vendor, comments := "re-peat", []string(nil)
+	kept := make([]flacBlock, 0, len(blocks)+2)
+	for _, it := range blocks {
+		switch it.kind {
+		case flacStreamInfo:
+			if len(it.data) >= 18 {
+				if n := binary.BigEndian.Uint64(it.data[10:]) & (1<<36 - 1); n > 0 {
+					totalSamples = n
+				}
+			}
+			kept = append(kept, it)
+		case flacVorbisComment:
+			vendor, comments = parseVorbisComment(it.data)
+		case flacCueSheet:
+		default:
+			kept = append(kept, it)
+		}
+	}
+	if len(kept) == 0 || kept[0].kind != flacStreamInfo {
+		return errors.New("FLAC has no STREAMINFO block")
+	}
+	comments = slices.DeleteFunc(comments, func(it string) bool {
+		key, _, _ := strings.Cut(it, "=")
+		key = strings.ToUpper(key)
+		return key == "CUESHEET" || chapterKey.MatchString(key)
+	})
+	for idx, it := range doc.Markers {
+		time := formatChapterTime(samplesToSeconds(it.Samples, doc.Audio.SampleRate))
+		comments = append(comments, fmt.Sprintf("CHAPTER%03d=%s", idx+1, time))
+		if it.Name != "" {
+			comments = append(comments, fmt.Sprintf("CHAPTER%03dNAME=%s", idx+1, it.Name))
+		}
+	}
+	// Vorbis comment goes right after STREAMINFO, where most players look for it
+	kept = slices.Insert(kept, 1,
+		flacBlock{kind: flacVorbisComment, data: encodeVorbisComment(vendor, comments)},
+		flacBlock{kind: flacCueSheet, data: encodeCueSheetBlock(doc.Markers, totalSamples)},
+	)
+
+	if _, err := w.Write([]byte("fLaC")); err != nil {
+		return err
+	}
+	for idx, it := range kept {
+		if len(it.data) > flacMaxBlockLength {
+			return fmt.Errorf("metadata block %d is too long", it.kind)
+		}
+		kind := it.kind
+		if idx == len(kept)-1 {
+			kind |= flacLastBlock
+		}
+		length := len(it.data)
+		if _, err := w.Write([]byte{kind, byte(length >> 16), byte(length >> 8), byte(length)}); err != nil {
+			return err
+		}
+		if _, err := w.Write(it.data); err != nil {
+			return err
+		}
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// "hh:mm:ss.fff" as the chapter extension wants it
+func formatChapterTime(seconds float64) string {
+	ms := int(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+func encodeVorbisComment(vendor string, comments []string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(vendor)))
+	data = append(data, vendor...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(comments)))
+	for _, it := range comments {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(it)))
+		data = append(data, it...)
+	}
+	return data
+}
+
+// Non-CD cue sheet with a track for every marker and a lead-out at the end of the audio
+func encodeCueSheetBlock(markers tm.TimeMarkers, totalSamples uint64) []byte {
+	data := make([]byte, flacCueSheetHead)
+	data[flacCueSheetHead-1] = byte(len(markers) + 1)
+	track := func(offset uint64, number byte, indexes int) {
+		head := make([]byte, flacCueSheetTrack)
+		binary.BigEndian.PutUint64(head, offset)
+		head[8] = number
+		head[flacCueSheetTrack-1] = byte(indexes)
+		data = append(data, head...)
+	}
+	for idx, it := range markers {
+		track(uint64(it.Samples), byte(idx+1), 1)
+		index := make([]byte, flacCueSheetIndex)
+		index[8] = 1
+		data = append(data, index...)
+	}
+	track(totalSamples, flacLeadOut, 0)
+	return data
+}
@@ -0,0 +1,105 @@
+package markerio
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+const (
+	htmlThumbW = 120
+	htmlThumbH = 32
+)
+
+var sheetTemplate = template.Must(template.New("sheet").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+@page { size: A4; margin: 14mm; }
+body { font: 10pt/1.35 system-ui, sans-serif; color: #1a1a1a; margin: 24px; }
+h1 { font-size: 16pt; margin: 0 0 6px; }
+.info { color: #666; margin: 0 0 14px; }
+.info span { margin-right: 18px; }
+table { width: 100%; border-collapse: collapse; }
+th { background: #eee; text-align: left; }
+th, td { padding: 4px 6px; vertical-align: top; border-bottom: 1px solid #d1d1d1; }
+tr { break-inside: avoid; }
+td.notes { white-space: pre-wrap; }
+td.nowrap { white-space: nowrap; }
+svg { display: block; background: #f5f5f5; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="info">{{range .Info}}<span>{{index . 0}}: {{index . 1}}</span>{{end}}{{if .Filter}}<br>{{.Filter}}{{end}}</p>
+<table>
+<thead><tr><th>#</th><th>{{.Labels.Time}}</th>{{if .HasThumbs}}<th></th>{{end}}<th>{{.Labels.Name}}</th><th>{{.Labels.Tags}}</th><th>{{.Labels.Notes}}</th></tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.Number}}</td><td class="nowrap">{{.Time}}</td>{{if $.HasThumbs}}<td>{{with .Thumb}}<svg width="{{$.ThumbW}}" height="{{$.ThumbH}}" viewBox="0 0 {{$.ThumbW}} {{$.ThumbH}}"><path d="{{.Wave}}" stroke="#595959" stroke-width="1.4"/><path d="{{.Marker}}" stroke="#b3261e"/></svg>{{end}}</td>{{end}}<td>{{.Name}}</td><td>{{.Tags}}</td><td class="notes">{{.Notes}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+type htmlSheet struct {
+	Title     string
+	Info      [][2]string
+	Filter    string
+	Labels    SheetLabels
+	HasThumbs bool
+	ThumbW    int
+	ThumbH    int
+	Rows      []htmlRow
+}
+
+type htmlRow struct {
+	Number, Time, Name, Tags, Notes string
+	Thumb                           *htmlThumb
+}
+
+// SVG path data of the waveform and of the marker line
+type htmlThumb struct {
+	Wave   string
+	Marker string
+}
+
+// Self-contained page, which prints as a cue sheet. Thumbnails are inline SVG
+func EncodeHTML(w io.Writer, doc Document) error {
+	sheet := htmlSheet{
+		Title:     doc.Sheet.title(doc),
+		Info:      doc.Sheet.Info,
+		Filter:    doc.Sheet.Filter,
+		Labels:    doc.Sheet.labels(),
+		HasThumbs: len(doc.Sheet.Thumbnails) > 0,
+		ThumbW:    htmlThumbW,
+		ThumbH:    htmlThumbH,
+	}
+	for _, it := range sheetRows(doc) {
+		row := htmlRow{Number: it.number, Time: it.time, Name: it.name, Tags: it.tags, Notes: it.notes}
+		if it.thumb != nil && len(it.thumb.Peaks) > 0 {
+			row.Thumb = svgThumbnail(*it.thumb)
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+	return sheetTemplate.Execute(w, sheet)
+}
+
+func svgThumbnail(t Thumbnail) *htmlThumb {
+	var wave strings.Builder
+	colW := float64(htmlThumbW) / float64(len(t.Peaks))
+	mid := float64(htmlThumbH) / 2
+	for idx, it := range t.Peaks {
+		x := (float64(idx) + 0.5) * colW
+		lo, hi := mid-float64(it[1])*mid, mid-float64(it[0])*mid
+		fmt.Fprintf(&wave, "M%.1f %.1fV%.1f", x, lo, max(hi, lo+0.5))
+	}
+	x := t.Marker * htmlThumbW
+	return &htmlThumb{Wave: wave.String(), Marker: fmt.Sprintf("M%.1f 0V%d", x, htmlThumbH)}
+}
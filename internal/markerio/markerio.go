@@ -0,0 +1,230 @@
+package markerio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+var (
+	ErrOutOfTrack = errors.New("time is outside of the audio")
+	ErrLimit      = fmt.Errorf("no more than %d markers are allowed", tm.Limit)
+)
+
+// Audio the markers belong to
+type Target struct {
+	Audio     audio.AudioMeta
+	AudioName string // file name of the audio, without directories
+	AudioPath string
+	Tempo     float64 // bpm of the project, 0 if it has none
+}
+
+// Project tempo, or the default one of DAWs
+func (t Target) tempo() float64 {
+	if t.Tempo > 0 {
+		return t.Tempo
+	}
+	return DefaultTempo
+}
+
+// Markers together with what other formats need to know about the audio they belong to
+type Document struct {
+	Markers tm.TimeMarkers
+	Target
+	Sheet     Sheet // only printable formats use it
+	WithNotes bool  // for formats where notes are optional
+}
+
+// Problem with a single row (line, cue, event) of the imported file, the rest is still imported
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("%d: %v", e.Row, e.Err)
+}
+
+// What gets lost when markers are written in a format
+type IssueKind int
+
+const (
+	IssueRounded   IssueKind = iota // time is moved to the precision of the format
+	IssueReplaced                   // characters the format can't carry are replaced
+	IssueTruncated                  // text is longer than the format allows
+	IssueDropped                    // marker doesn't fit into the format at all
+	IssueNonASCII                   // text may show up garbled where the format has no Unicode
+)
+
+type Issue struct {
+	Marker int // index in the document
+	Kind   IssueKind
+	Shift  float64 // seconds the marker is moved by, for IssueRounded
+}
+
+// Exporters with "Check" can lose something, which the user should know about before export.
+// Exporters with "Supports" are offered only for the audio they accept
+type Exporter struct {
+	Name       string
+	Ext        string
+	Encode     func(w io.Writer, doc Document) error
+	Check      func(doc Document) []Issue
+	Supports   func(t Target) bool
+	Thumbnails bool // can draw waveforms of the markers
+	Notes      bool // notes are written only if the user wants them
+	Tempo      bool // times are written in beats of the project tempo
+}
+
+// Importers either decode markers right away or read a table, which columns are matched by the user.
+// Importers with "Detect" take only the files which beginning they recognise
+type Importer struct {
+	Name      string
+	Exts      []string
+	Decode    func(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error)
+	ReadTable func(r io.Reader) (Table, error)
+	Detect    func(head []byte) bool
+}
+
+var Exporters = []Exporter{
+	{Name: "Audacity", Ext: ".txt", Encode: EncodeAudacity},
+	{Name: "CSV", Ext: ".csv", Encode: EncodeCSV},
+	{Name: "TSV", Ext: ".tsv", Encode: EncodeTSV},
+	{Name: "CUE", Ext: ".cue", Encode: EncodeCUE, Check: CheckCUE},
+	{Name: "MIDI", Ext: ".mid", Encode: EncodeSMF, Tempo: true},
+	{Name: "Reaper", Ext: ".csv", Encode: EncodeReaper},
+	{Name: "PDF", Ext: ".pdf", Encode: EncodePDF, Thumbnails: true},
+	{Name: "HTML", Ext: ".html", Encode: EncodeHTML, Thumbnails: true},
+	{Name: "SRT", Ext: ".srt", Encode: EncodeSRT, Notes: true},
+	{Name: "WebVTT", Ext: ".vtt", Encode: EncodeVTT, Notes: true},
+	{Name: "WAV", Ext: ".wav", Encode: EncodeWAV, Supports: isWAV},
+	{Name: "FLAC", Ext: ".flac", Encode: EncodeFLAC, Supports: isFLAC},
+}
+
+var Importers = []Importer{
+	{Name: "Audacity", Exts: []string{".txt"}, Decode: DecodeAudacity},
+	{Name: "Reaper", Exts: []string{".csv"}, Decode: DecodeReaper, Detect: isReaperCSV},
+	{Name: "CSV", Exts: []string{".csv"}, ReadTable: ReadCSV},
+	{Name: "TSV", Exts: []string{".tsv", ".tab"}, ReadTable: ReadTSV},
+	{Name: "CUE", Exts: []string{".cue"}, Decode: DecodeCUE},
+	{Name: "MIDI", Exts: []string{".mid", ".midi", ".smf"}, Decode: DecodeSMF},
+	{Name: "WAV", Exts: []string{".wav", ".wave"}, Decode: DecodeWAV},
+	{Name: "FLAC", Exts: []string{".flac"}, Decode: DecodeFLAC},
+}
+
+// Exporters which accept the audio
+func ExportersFor(t Target) []Exporter {
+	var exporters []Exporter
+	for _, it := range Exporters {
+		if it.Supports == nil || it.Supports(t) {
+			exporters = append(exporters, it)
+		}
+	}
+	return exporters
+}
+
+// Markers written into the audio file itself, if it's of a format that can carry them.
+// The format is recognised by the content, nothing is returned for other formats
+func ReadEmbedded(path string, t Target) (tm.TimeMarkers, []RowError, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	format, err := audio.ReadFormat(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var decode func(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error)
+	switch format {
+	case audio.FormatWAV:
+		decode = DecodeWAV
+	case audio.FormatFLAC:
+		decode = DecodeFLAC
+	default:
+		return nil, nil, nil
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	// Not buffered, so audio of a WAV is skipped by seeking
+	return decode(file, t)
+}
+
+func isWAV(t Target) bool {
+	return audioFormat(t.AudioPath) == audio.FormatWAV
+}
+
+func isFLAC(t Target) bool {
+	return audioFormat(t.AudioPath) == audio.FormatFLAC
+}
+
+// Unreadable audio is of unknown format, so it doesn't get embedded markers
+func audioFormat(path string) audio.Format {
+	file, err := os.Open(path)
+	if err != nil {
+		return audio.FormatUnknown
+	}
+	defer file.Close()
+	format, err := audio.ReadFormat(file)
+	if err != nil {
+		return audio.FormatUnknown
+	}
+	return format
+}
+
+// Extensions offered by the file chooser on import
+func ImportExtensions() []string {
+	var exts []string
+	for _, it := range Importers {
+		for _, ext := range it.Exts {
+			if !slices.Contains(exts, ext) {
+				exts = append(exts, ext)
+			}
+		}
+	}
+	return exts
+}
+
+// Importer by the file extension and the first bytes of the file
+func ImporterFor(path string, head []byte) (Importer, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, it := range Importers {
+		if slices.Contains(it.Exts, ext) && (it.Detect == nil || it.Detect(head)) {
+			return it, true
+		}
+	}
+	return Importer{}, false
+}
+
+func secondsToSamples(seconds float64, sampleRate int) int {
+	return int(math.Round(seconds * float64(sampleRate)))
+}
+
+func samplesToSeconds(samples int, sampleRate int) float64 {
+	return float64(samples) / float64(sampleRate)
+}
+
+// Imported markers get the same capacity for tags as the created ones
+func newMarker(samples, end int, name string) tm.TimeMarker {
+	return tm.TimeMarker{
+		Samples:      samples,
+		End:          end,
+		Name:         name,
+		CategoryTags: make([]string, 0, tm.TagsLimit),
+	}
+}
+
+func checkTime(seconds float64, a audio.AudioMeta) error {
+	if seconds < 0 || seconds > a.Seconds || math.IsNaN(seconds) {
+		return fmt.Errorf("%w: %.3f s", ErrOutOfTrack, seconds)
+	}
+	return nil
+}
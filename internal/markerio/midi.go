@@ -0,0 +1,245 @@
+package markerio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+// Used when the project has no tempo, it's what DAWs start with
+const DefaultTempo = 120
+
+// Project tempo range, the same as most DAWs accept
+const (
+	MinTempo = 20
+	MaxTempo = 400
+)
+
+const (
+	smfDivision  = 960 // ticks per quarter note, 0.26 ms at 120 bpm
+	smfMarker    = 0x06
+	smfCuePoint  = 0x07
+	smfTrackName = 0x03
+	smfTempo     = 0x51
+	smfEndTrack  = 0x2f
+)
+
+var ErrNotMIDI = errors.New("not a Standard MIDI File")
+
+// Format 0 file with the project tempo and a marker meta event for every marker. Region ends are not written
+func EncodeSMF(w io.Writer, doc Document) error {
+	var track []byte
+	lastTick := 0
+	event := func(tick int, kind byte, data []byte) {
+		track = appendVLQ(track, uint32(tick-lastTick))
+		lastTick = tick
+		track = append(track, 0xff, kind)
+		track = appendVLQ(track, uint32(len(data)))
+		track = append(track, data...)
+	}
+	bpm := doc.tempo()
+	tempo := uint32(math.Round(60_000_000 / bpm)) // microseconds per quarter note
+	event(0, smfTrackName, []byte(doc.AudioName))
+	event(0, smfTempo, []byte{byte(tempo >> 16), byte(tempo >> 8), byte(tempo)})
+	for _, it := range doc.Markers {
+		event(smfTicks(it.Samples, doc.Audio.SampleRate, bpm), smfMarker, []byte(it.Name))
+	}
+	event(lastTick, smfEndTrack, nil)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("MThd")
+	binary.Write(bw, binary.BigEndian, uint32(6))
+	binary.Write(bw, binary.BigEndian, []uint16{0, 1, smfDivision})
+	bw.WriteString("MTrk")
+	binary.Write(bw, binary.BigEndian, uint32(len(track)))
+	bw.Write(track)
+	return bw.Flush()
+}
+
+func smfTicks(samples, sampleRate int, bpm float64) int {
+	return int(math.Round(samplesToSeconds(samples, sampleRate) * bpm / 60 * smfDivision))
+}
+
+func appendVLQ(b []byte, v uint32) []byte {
+	var buf [5]byte
+	idx := len(buf) - 1
+	buf[idx] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		idx--
+		buf[idx] = byte(v&0x7f) | 0x80
+	}
+	return append(b, buf[idx:]...)
+}
+
+type smfText struct {
+	tick int
+	text string
+}
+
+type smfTempoChange struct {
+	tick          int
+	microsPerBeat int
+}
+
+// Marker and cue point meta events of all tracks, timed by the tempo map of the file
+func DecodeSMF(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error) {
+	br := bufio.NewReader(r)
+	var head [14]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil || string(head[:4]) != "MThd" {
+		return nil, nil, ErrNotMIDI
+	}
+	headLen := binary.BigEndian.Uint32(head[4:])
+	if headLen < 6 {
+		return nil, nil, ErrNotMIDI
+	}
+	if _, err := br.Discard(int(headLen) - 6); err != nil {
+		return nil, nil, err
+	}
+	division := binary.BigEndian.Uint16(head[12:])
+
+	var texts []smfText
+	var tempos []smfTempoChange
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(br, chunk[:]); err != nil {
+			break
+		}
+		// The length can't be trusted, so the buffer grows only as far as the file really goes
+		size := int64(binary.BigEndian.Uint32(chunk[4:]))
+		data, err := io.ReadAll(io.LimitReader(br, size))
+		if err == nil && int64(len(data)) < size {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%q chunk: %w", chunk[:4], err)
+		}
+		if string(chunk[:4]) != "MTrk" {
+			continue
+		}
+		if err := parseSMFTrack(data, &texts, &tempos); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	toSeconds := smfClock(division, tempos)
+	slices.SortStableFunc(texts, func(a, b smfText) int { return a.tick - b.tick })
+	markers := tm.NewTimeMarkers()
+	var rowErrs []RowError
+	for idx, it := range texts {
+		seconds := toSeconds(it.tick)
+		if err := checkTime(seconds, t.Audio); err != nil {
+			rowErrs = append(rowErrs, RowError{Row: idx + 1, Err: err})
+			continue
+		}
+		if !markers.AttachNewMarker(newMarker(secondsToSamples(seconds, t.Audio.SampleRate), 0, it.text)) {
+			rowErrs = append(rowErrs, RowError{Row: idx + 1, Err: ErrLimit})
+		}
+	}
+	markers.Sort()
+	return markers, rowErrs, nil
+}
+
+func parseSMFTrack(data []byte, texts *[]smfText, tempos *[]smfTempoChange) error {
+	errTruncated := errors.New("MIDI track is truncated")
+	readVLQ := func() (int, bool) {
+		v := 0
+		for idx := 0; idx < 4 && len(data) > 0; idx++ {
+			b := data[0]
+			data = data[1:]
+			v = v<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				return v, true
+			}
+		}
+		return 0, false
+	}
+	tick := 0
+	var status byte
+	for len(data) > 0 {
+		delta, ok := readVLQ()
+		if !ok || len(data) == 0 {
+			return errTruncated
+		}
+		tick += delta
+		if data[0]&0x80 != 0 {
+			status = data[0]
+			data = data[1:]
+		}
+		switch {
+		case status == 0xff:
+			if len(data) == 0 {
+				return errTruncated
+			}
+			kind := data[0]
+			data = data[1:]
+			length, ok := readVLQ()
+			if !ok || length > len(data) {
+				return errTruncated
+			}
+			body := data[:length]
+			data = data[length:]
+			switch kind {
+			case smfMarker, smfCuePoint:
+				*texts = append(*texts, smfText{tick: tick, text: string(body)})
+			case smfTempo:
+				if length == 3 {
+					*tempos = append(*tempos, smfTempoChange{tick: tick, microsPerBeat: int(body[0])<<16 | int(body[1])<<8 | int(body[2])})
+				}
+			case smfEndTrack:
+				return nil
+			}
+		case status == 0xf0 || status == 0xf7:
+			length, ok := readVLQ()
+			if !ok || length > len(data) {
+				return errTruncated
+			}
+			data = data[length:]
+		case status >= 0x80:
+			// Program change and channel pressure have one data byte, the rest of channel messages two
+			n := 2
+			if status&0xf0 == 0xc0 || status&0xf0 == 0xd0 {
+				n = 1
+			}
+			if n > len(data) {
+				return errTruncated
+			}
+			data = data[n:]
+		default:
+			return errors.New("MIDI event without status")
+		}
+	}
+	return nil
+}
+
+// Seconds of a tick, either by the tempo map or by SMPTE frames
+func smfClock(division uint16, tempos []smfTempoChange) func(tick int) float64 {
+	if division&0x8000 != 0 {
+		fps := float64(-int8(division >> 8))
+		if fps == 29 {
+			fps = 29.97
+		}
+		ticksPerFrame := float64(division & 0xff)
+		return func(tick int) float64 {
+			return float64(tick) / fps / ticksPerFrame
+		}
+	}
+	ppq := float64(max(division, 1))
+	slices.SortStableFunc(tempos, func(a, b smfTempoChange) int { return a.tick - b.tick })
+	return func(tick int) float64 {
+		seconds, lastTick, micros := 0.0, 0, 500_000 // 120 bpm until the first tempo event
+		for _, it := range tempos {
+			if it.tick >= tick {
+				break
+			}
+			seconds += float64(it.tick-lastTick) / ppq * float64(micros) / 1e6
+			lastTick, micros = it.tick, it.microsPerBeat
+		}
+		return seconds + float64(tick-lastTick)/ppq*float64(micros)/1e6
+	}
+}
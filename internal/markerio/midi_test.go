@@ -0,0 +1,100 @@
+package markerio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func TestSMFRoundTrip(t *testing.T) {
+	a := testMeta()
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(0, 0, "Intro"))
+	markers.AttachNewMarker(newMarker(48000*30+11, 0, "Bridge"))
+	var buf bytes.Buffer
+	if err := EncodeSMF(&buf, Document{Markers: markers, Target: Target{Audio: a, AudioName: "take.wav"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, rowErrs, err := DecodeSMF(&buf, Target{Audio: a})
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	if len(got) != 2 || got[0].Name != "Intro" || got[1].Name != "Bridge" {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+	// One tick at 960 ppq and 120 bpm is 25 samples at 48 kHz
+	if diff := got[1].Samples - (48000*30 + 11); diff < -13 || diff > 13 {
+		t.Errorf("marker is off by %d samples", diff)
+	}
+}
+
+// Format 1 with tempo in the first track, a marker in the second one after notes with running status
+func TestSMFTempoMap(t *testing.T) {
+	tempo := []byte{
+		0x00, 0xff, 0x51, 0x03, 0x07, 0xa1, 0x20, // 120 bpm
+		0x83, 0x60, 0xff, 0x51, 0x03, 0x0f, 0x42, 0x40, // 60 bpm after 480 ticks
+		0x00, 0xff, 0x2f, 0x00,
+	}
+	notes := []byte{
+		0x00, 0x90, 0x3c, 0x64,
+		0x83, 0x60, 0x3c, 0x00, // note off with running status at 480
+		0x83, 0x60, 0xff, 0x06, 0x05, 'C', 'o', 'd', 'a', '!', // marker at 960
+		0x00, 0xff, 0x2f, 0x00,
+	}
+	var file bytes.Buffer
+	file.WriteString("MThd")
+	binary.Write(&file, binary.BigEndian, uint32(6))
+	binary.Write(&file, binary.BigEndian, []uint16{1, 2, 480})
+	for _, track := range [][]byte{tempo, notes} {
+		file.WriteString("MTrk")
+		binary.Write(&file, binary.BigEndian, uint32(len(track)))
+		file.Write(track)
+	}
+	got, rowErrs, err := DecodeSMF(&file, Target{Audio: testMeta()})
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	// Half a second of the first beat and a second of the next one
+	if len(got) != 1 || got[0].Name != "Coda!" || got[0].Samples != 48000*1.5 {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+}
+
+func TestSMFProjectTempo(t *testing.T) {
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(48000*2, 0, "Verse"))
+	var buf bytes.Buffer
+	target := Target{Audio: testMeta(), Tempo: 90}
+	if err := EncodeSMF(&buf, Document{Markers: markers, Target: target}); err != nil {
+		t.Fatal(err)
+	}
+	// 666667 microseconds per quarter note
+	if !bytes.Contains(buf.Bytes(), []byte{0xff, 0x51, 0x03, 0x0a, 0x2c, 0x2b}) {
+		t.Fatal("tempo event of 90 bpm is not written")
+	}
+	// Three beats at 90 bpm
+	if !bytes.Contains(buf.Bytes(), append(appendVLQ(nil, 3*smfDivision), 0xff, smfMarker)) {
+		t.Fatal("marker is not timed by the project tempo")
+	}
+	got, _, err := DecodeSMF(&buf, Target{Audio: testMeta()})
+	if err != nil || len(got) != 1 || got[0].Samples != 48000*2 {
+		t.Fatalf("unexpected markers %+v, err %v", got, err)
+	}
+}
+
+func TestSMFChunkLongerThanFile(t *testing.T) {
+	var file bytes.Buffer
+	file.WriteString("MThd")
+	binary.Write(&file, binary.BigEndian, uint32(6))
+	binary.Write(&file, binary.BigEndian, []uint16{0, 1, 480})
+	file.WriteString("MTrk")
+	binary.Write(&file, binary.BigEndian, uint32(0xfffffff0))
+	file.Write([]byte{0x00, 0xff, 0x2f, 0x00})
+	if _, _, err := DecodeSMF(&file, Target{Audio: testMeta()}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected unexpected EOF, got %v", err)
+	}
+}
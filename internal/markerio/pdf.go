@@ -0,0 +1,425 @@
+package markerio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// A4 in points
+const (
+	pdfPageW   = 595.28
+	pdfPageH   = 841.89
+	pdfMargin  = 40.0
+	pdfFooterH = 20.0
+
+	pdfFontSize  = 9.0
+	pdfLineH     = 12.0
+	pdfTitleSize = 16.0
+	pdfCellPad   = 4.0
+	pdfThumbW    = 72.0
+	pdfThumbH    = 20.0
+	pdfNumberW   = 26.0
+	pdfTimeW     = 62.0
+)
+
+type pdfColor [3]float64
+
+var (
+	pdfText      = pdfColor{0.1, 0.1, 0.1}
+	pdfMuted     = pdfColor{0.4, 0.4, 0.4}
+	pdfHeaderBg  = pdfColor{0.93, 0.93, 0.93}
+	pdfRule      = pdfColor{0.82, 0.82, 0.82}
+	pdfThumbBg   = pdfColor{0.96, 0.96, 0.96}
+	pdfWave      = pdfColor{0.35, 0.35, 0.35}
+	pdfMarkerCol = pdfColor{0.7, 0.15, 0.12}
+)
+
+// Paginated A4 cue sheet. Go fonts are embedded, so names in any script they cover are printed
+func EncodePDF(w io.Writer, doc Document) error {
+	regular, err := newPDFFont("F1", "GoRegular", goregular.TTF)
+	if err != nil {
+		return err
+	}
+	bold, err := newPDFFont("F2", "GoBold", gobold.TTF)
+	if err != nil {
+		return err
+	}
+	l := &pdfLayout{doc: doc, labels: doc.Sheet.labels(), regular: regular, bold: bold}
+	l.layout()
+
+	pdf := &pdfWriter{}
+	catalog := pdf.reserve()
+	pages := pdf.reserve()
+	fonts := fmt.Sprintf("/Font << /F1 %d 0 R /F2 %d 0 R >>", regular.embed(pdf), bold.embed(pdf))
+	var kids []string
+	for _, it := range l.pages {
+		content := pdf.stream("", it.Bytes())
+		page := pdf.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << %s >> /Contents %d 0 R >>",
+			pages, pdfPageW, pdfPageH, fonts, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	pdf.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	pdf.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	info := pdf.add(fmt.Sprintf("<< /Title %s /Producer (re-peat) >>", pdfTextString(doc.Sheet.title(doc))))
+	return pdf.write(w, catalog, info)
+}
+
+type pdfLayout struct {
+	doc     Document
+	labels  SheetLabels
+	regular *pdfFont
+	bold    *pdfFont
+	pages   []*bytes.Buffer
+	page    *bytes.Buffer
+	y       float64 // top of the free space, from the bottom of the page
+	columns []pdfColumn
+}
+
+type pdfColumn struct {
+	x, w  float64
+	title string
+	cell  func(r sheetRow) string
+}
+
+func (l *pdfLayout) layout() {
+	hasThumbs := len(l.doc.Sheet.Thumbnails) > 0
+	rest := pdfPageW - 2*pdfMargin - pdfNumberW - pdfTimeW
+	if hasThumbs {
+		rest -= pdfThumbW + 2*pdfCellPad
+	}
+	x := pdfMargin
+	column := func(w float64, title string, cell func(r sheetRow) string) {
+		l.columns = append(l.columns, pdfColumn{x: x, w: w, title: title, cell: cell})
+		x += w
+	}
+	column(pdfNumberW, "#", func(r sheetRow) string { return r.number })
+	column(pdfTimeW, l.labels.Time, func(r sheetRow) string { return r.time })
+	if hasThumbs {
+		column(pdfThumbW+2*pdfCellPad, "", nil)
+	}
+	column(rest*0.3, l.labels.Name, func(r sheetRow) string { return r.name })
+	column(rest*0.2, l.labels.Tags, func(r sheetRow) string { return r.tags })
+	column(rest*0.5, l.labels.Notes, func(r sheetRow) string { return r.notes })
+
+	l.newPage()
+	l.heading()
+	l.tableHeader()
+	for _, it := range sheetRows(l.doc) {
+		l.row(it)
+	}
+	for idx, it := range l.pages {
+		footer := fmt.Sprintf(l.labels.Page, idx+1, len(l.pages))
+		fw := l.regular.width(footer, pdfFontSize)
+		pdfTextAt(it, l.regular, pdfFontSize, pdfMuted, (pdfPageW-fw)/2, pdfMargin-pdfFontSize, footer)
+	}
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = pdfPageH - pdfMargin
+}
+
+func (l *pdfLayout) heading() {
+	width := pdfPageW - 2*pdfMargin
+	for _, line := range wrapText(l.bold, l.doc.Sheet.title(l.doc), pdfTitleSize, width) {
+		l.y -= pdfTitleSize * 1.25
+		pdfTextAt(l.page, l.bold, pdfTitleSize, pdfText, pdfMargin, l.y, line)
+	}
+	l.y -= pdfLineH / 2
+	info := make([]string, 0, len(l.doc.Sheet.Info))
+	for _, it := range l.doc.Sheet.Info {
+		info = append(info, it[0]+": "+it[1])
+	}
+	lines := wrapText(l.regular, strings.Join(info, "    "), pdfFontSize, width)
+	if l.doc.Sheet.Filter != "" {
+		lines = append(lines, wrapText(l.regular, l.doc.Sheet.Filter, pdfFontSize, width)...)
+	}
+	for _, line := range lines {
+		l.y -= pdfLineH
+		pdfTextAt(l.page, l.regular, pdfFontSize, pdfMuted, pdfMargin, l.y, line)
+	}
+	l.y -= pdfLineH
+}
+
+func (l *pdfLayout) tableHeader() {
+	h := pdfLineH + 2*pdfCellPad
+	pdfRect(l.page, pdfHeaderBg, pdfMargin, l.y-h, pdfPageW-2*pdfMargin, h)
+	for _, it := range l.columns {
+		pdfTextAt(l.page, l.bold, pdfFontSize, pdfText, it.x+pdfCellPad, l.y-pdfCellPad-pdfFontSize, it.title)
+	}
+	l.y -= h
+}
+
+func (l *pdfLayout) row(r sheetRow) {
+	// A row never gets taller than an empty page
+	maxLines := int(math.Floor((pdfPageH - 2*pdfMargin - pdfFooterH - 3*pdfLineH - 4*pdfCellPad) / pdfLineH))
+	cells := make([][]string, len(l.columns))
+	lines := 1
+	for idx, it := range l.columns {
+		if it.cell == nil {
+			continue
+		}
+		cells[idx] = wrapText(l.regular, it.cell(r), pdfFontSize, it.w-2*pdfCellPad)
+		if len(cells[idx]) > maxLines {
+			cells[idx] = cells[idx][:maxLines]
+			cells[idx][maxLines-1] += "…"
+		}
+		lines = max(lines, len(cells[idx]))
+	}
+	h := float64(lines)*pdfLineH + 2*pdfCellPad
+	if r.thumb != nil {
+		h = max(h, pdfThumbH+2*pdfCellPad)
+	}
+	if l.y-h < pdfMargin+pdfFooterH {
+		l.newPage()
+		l.tableHeader()
+	}
+	for idx, it := range l.columns {
+		if it.cell == nil {
+			if r.thumb != nil {
+				pdfThumbnail(l.page, *r.thumb, it.x+pdfCellPad, l.y-pdfCellPad-pdfThumbH)
+			}
+			continue
+		}
+		for n, line := range cells[idx] {
+			baseline := l.y - pdfCellPad - pdfFontSize - float64(n)*pdfLineH
+			pdfTextAt(l.page, l.regular, pdfFontSize, pdfText, it.x+pdfCellPad, baseline, line)
+		}
+	}
+	l.y -= h
+	pdfRect(l.page, pdfRule, pdfMargin, l.y, pdfPageW-2*pdfMargin, 0.5)
+}
+
+func pdfThumbnail(w *bytes.Buffer, t Thumbnail, x, y float64) {
+	pdfRect(w, pdfThumbBg, x, y, pdfThumbW, pdfThumbH)
+	colW := pdfThumbW / float64(len(t.Peaks))
+	mid := y + pdfThumbH/2
+	for idx, it := range t.Peaks {
+		lo, hi := float64(it[0])*pdfThumbH/2, float64(it[1])*pdfThumbH/2
+		pdfRect(w, pdfWave, x+float64(idx)*colW, mid+lo, colW*0.8, max(hi-lo, 0.3))
+	}
+	pdfRect(w, pdfMarkerCol, x+t.Marker*pdfThumbW, y, 0.8, pdfThumbH)
+}
+
+func pdfRect(w *bytes.Buffer, c pdfColor, x, y, width, height float64) {
+	fmt.Fprintf(w, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", c[0], c[1], c[2], x, y, width, height)
+}
+
+func pdfTextAt(w *bytes.Buffer, f *pdfFont, size float64, c pdfColor, x, y float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(w, "BT %.3f %.3f %.3f rg /%s %.1f Tf %.2f %.2f Td <%s> Tj ET\n", c[0], c[1], c[2], f.resource, size, x, y, f.hex(s))
+}
+
+// Lines of the text that fit into the width. Words longer than a line are split
+func wrapText(f *pdfFont, s string, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if f.width(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && f.width(line+string(r), size) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// TrueType font embedded whole, text is written as glyph indexes
+type pdfFont struct {
+	resource string
+	baseName string
+	ttf      []byte
+	font     *sfnt.Font
+	buf      sfnt.Buffer
+	upem     fixed.Int26_6
+	glyphs   map[rune]pdfGlyph
+}
+
+type pdfGlyph struct {
+	index sfnt.GlyphIndex
+	width float64 // in 1/1000 of em
+}
+
+func newPDFFont(resource, baseName string, ttf []byte) (*pdfFont, error) {
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return &pdfFont{
+		resource: resource,
+		baseName: baseName,
+		ttf:      ttf,
+		font:     f,
+		upem:     fixed.I(int(f.UnitsPerEm())),
+		glyphs:   map[rune]pdfGlyph{},
+	}, nil
+}
+
+func (f *pdfFont) glyph(r rune) pdfGlyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	var g pdfGlyph
+	g.index, _ = f.font.GlyphIndex(&f.buf, r)
+	if advance, err := f.font.GlyphAdvance(&f.buf, g.index, f.upem, font.HintingNone); err == nil {
+		g.width = float64(advance) / float64(f.upem) * 1000
+	}
+	f.glyphs[r] = g
+	return g
+}
+
+func (f *pdfFont) width(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		w += f.glyph(r).width
+	}
+	return w * size / 1000
+}
+
+func (f *pdfFont) hex(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&b, "%04X", uint16(f.glyph(r).index))
+	}
+	return b.String()
+}
+
+// Type0 font with Identity-H encoding, so glyph indexes are used as they are
+func (f *pdfFont) embed(pdf *pdfWriter) int {
+	units := func(v fixed.Int26_6) int {
+		return int(math.Round(float64(v) / float64(f.upem) * 1000))
+	}
+	metrics, _ := f.font.Metrics(&f.buf, f.upem, font.HintingNone)
+	bounds, _ := f.font.Bounds(&f.buf, f.upem, font.HintingNone)
+
+	runes := make([]rune, 0, len(f.glyphs))
+	for r := range f.glyphs {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	var widths, cmap strings.Builder
+	for idx, r := range runes {
+		g := f.glyphs[r]
+		fmt.Fprintf(&widths, "%d [%d] ", g.index, int(math.Round(g.width)))
+		if idx%100 == 0 {
+			if idx > 0 {
+				cmap.WriteString("endbfchar\n")
+			}
+			fmt.Fprintf(&cmap, "%d beginbfchar\n", min(100, len(runes)-idx))
+		}
+		fmt.Fprintf(&cmap, "<%04X> <", uint16(g.index))
+		for _, it := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&cmap, "%04X", it)
+		}
+		cmap.WriteString(">\n")
+	}
+	if len(runes) > 0 {
+		cmap.WriteString("endbfchar\n")
+	}
+
+	fontFile := pdf.stream(fmt.Sprintf("/Length1 %d", len(f.ttf)), f.ttf)
+	descriptor := pdf.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.baseName, units(bounds.Min.X), -units(bounds.Max.Y), units(bounds.Max.X), -units(bounds.Min.Y),
+		units(metrics.Ascent), -units(metrics.Descent), units(metrics.CapHeight), fontFile))
+	cid := pdf.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		f.baseName, descriptor, widths.String()))
+	toUnicode := pdf.stream("", []byte("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n"+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n"+
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n"+cmap.String()+
+		"endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n"))
+	return pdf.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.baseName, cid, toUnicode))
+}
+
+// Text string of the document info, in UTF-16 so any title is kept
+func pdfTextString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, it := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", it)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// Objects are numbered from 1 in the order they are added or reserved
+type pdfWriter struct {
+	objects []string
+}
+
+func (p *pdfWriter) reserve() int {
+	p.objects = append(p.objects, "")
+	return len(p.objects)
+}
+
+func (p *pdfWriter) set(n int, body string) {
+	p.objects[n-1] = body
+}
+
+func (p *pdfWriter) add(body string) int {
+	n := p.reserve()
+	p.set(n, body)
+	return n
+}
+
+// Compressed stream, "dict" holds additional entries of its dictionary
+func (p *pdfWriter) stream(dict string, data []byte) int {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+	return p.add(fmt.Sprintf("<< /Length %d /Filter /FlateDecode %s>>\nstream\n%s\nendstream", compressed.Len(), dict, compressed.Bytes()))
+}
+
+func (p *pdfWriter) write(w io.Writer, catalog, info int) error {
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(p.objects))
+	for idx, it := range p.objects {
+		offsets[idx] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", idx+1, it)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(p.objects)+1)
+	for _, it := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", it)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.objects)+1, catalog, info, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
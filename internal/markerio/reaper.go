@@ -0,0 +1,123 @@
+package markerio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+var reaperHeader = []string{"#", "Name", "Start", "End", "Length"}
+
+// Region/Marker Manager list: markers are "M1", regions are "R1" with their end and length
+func EncodeReaper(w io.Writer, doc Document) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reaperHeader); err != nil {
+		return err
+	}
+	rate := doc.Audio.SampleRate
+	markers, regions := 0, 0
+	for _, it := range doc.Markers {
+		row := []string{"", it.Name, formatTime(samplesToSeconds(it.Samples, rate)), "", ""}
+		if it.IsRegion() {
+			regions++
+			row[0] = fmt.Sprintf("R%d", regions)
+			row[3] = formatTime(samplesToSeconds(it.End, rate))
+			row[4] = formatTime(samplesToSeconds(it.End-it.Samples, rate))
+		} else {
+			markers++
+			row[0] = fmt.Sprintf("M%d", markers)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Reaper lists start with its own header, other CSV files go through column matching
+func isReaperCSV(head []byte) bool {
+	line, _, _ := bytes.Cut(bytes.TrimPrefix(head, []byte("\ufeff")), []byte("\n"))
+	return bytes.HasPrefix(bytes.ToLower(bytes.TrimSpace(line)), []byte("#,name,start"))
+}
+
+// Reaper writes times in the ruler format, which can also be measures and beats: "5.1.00"
+var reaperBeats = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)$`)
+
+func DecodeReaper(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	markers := tm.NewTimeMarkers()
+	var rowErrs []RowError
+	for idx, row := range rows {
+		if idx == 0 || isEmptyRow(row) {
+			continue
+		}
+		marker, err := decodeReaperRow(row, t)
+		if err == nil && !markers.AttachNewMarker(marker) {
+			err = ErrLimit
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Row: idx + 1, Err: err})
+		}
+	}
+	markers.Sort()
+	return markers, rowErrs, nil
+}
+
+func decodeReaperRow(row []string, t Target) (tm.TimeMarker, error) {
+	cell := func(idx int) string {
+		if idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+	start, err := parseReaperTime(cell(2), t.tempo())
+	if err != nil {
+		return tm.TimeMarker{}, fmt.Errorf("start: %w", err)
+	}
+	if err := checkTime(start, t.Audio); err != nil {
+		return tm.TimeMarker{}, err
+	}
+	end := 0
+	if strings.HasPrefix(strings.ToUpper(cell(0)), "R") && cell(3) != "" {
+		seconds, err := parseReaperTime(cell(3), t.tempo())
+		if err != nil {
+			return tm.TimeMarker{}, fmt.Errorf("end: %w", err)
+		}
+		end = min(secondsToSamples(seconds, t.Audio.SampleRate), t.Audio.MaxMonoSamples())
+	}
+	return newMarker(secondsToSamples(start, t.Audio.SampleRate), end, cell(1)), nil
+}
+
+// Measures and beats are counted in 4/4 at the project tempo, as there is no tempo map in the list
+func parseReaperTime(v string, bpm float64) (float64, error) {
+	match := reaperBeats.FindStringSubmatch(v)
+	if match == nil {
+		return parseTime(v)
+	}
+	measure, _ := strconv.Atoi(match[1])
+	beat, _ := strconv.Atoi(match[2])
+	hundredths, _ := strconv.Atoi(match[3])
+	if measure < 1 || beat < 1 {
+		return 0, fmt.Errorf("invalid position %q", v)
+	}
+	beats := float64((measure-1)*4+beat-1) + float64(hundredths)/100
+	return beats * 60 / bpm, nil
+}
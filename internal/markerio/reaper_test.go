@@ -0,0 +1,66 @@
+package markerio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func TestReaperRoundTrip(t *testing.T) {
+	a := testMeta()
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(48000, 0, "Intro"))
+	markers.AttachNewMarker(newMarker(48000*5, 48000*9, "Verse, first"))
+	var buf bytes.Buffer
+	if err := EncodeReaper(&buf, Document{Markers: markers, Target: Target{Audio: a}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "#,Name,Start,End,Length\nM1,Intro,00:01.000,,\nR1,\"Verse, first\",00:05.000,00:09.000,00:04.000\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if !isReaperCSV(buf.Bytes()) || isReaperCSV([]byte("#,Time,Samples,Name")) {
+		t.Fatal("Reaper header is not detected")
+	}
+	got, rowErrs, err := DecodeReaper(&buf, Target{Audio: a})
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	if len(got) != 2 || got[1].Name != "Verse, first" || got[1].Samples != 48000*5 || got[1].End != 48000*9 {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+}
+
+func TestReaperBeats(t *testing.T) {
+	input := strings.Join([]string{
+		"\ufeff#,Name,Start,End,Length,Color",
+		"M1,Downbeat,3.1.00,,,",
+		"R1,Fill,2.3.50,3.1.00,0.1.50,",
+		"M2,Broken,soon,,,",
+	}, "\n")
+	got, rowErrs, err := DecodeReaper(strings.NewReader(input), Target{Audio: testMeta()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two measures of 4/4 at 120 bpm are 4 seconds, beat 3.5 of the second measure is 3.25 seconds
+	if len(got) != 2 || got[0].Samples != 48000*3.25 || got[0].End != 48000*4 || got[1].Samples != 48000*4 {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+	if len(rowErrs) != 1 || rowErrs[0].Row != 4 {
+		t.Fatalf("unexpected row errors %v", rowErrs)
+	}
+}
+
+func TestReaperBeatsProjectTempo(t *testing.T) {
+	input := "#,Name,Start,End,Length\nM1,Downbeat,3.1.00,,\n"
+	got, rowErrs, err := DecodeReaper(strings.NewReader(input), Target{Audio: testMeta(), Tempo: 60})
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("unexpected errors: %v %v", err, rowErrs)
+	}
+	// Eight beats at 60 bpm
+	if len(got) != 1 || got[0].Samples != 48000*8 {
+		t.Fatalf("unexpected markers %+v", got)
+	}
+}
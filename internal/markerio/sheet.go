@@ -0,0 +1,127 @@
+package markerio
+
+import (
+	"strconv"
+	"strings"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+const (
+	thumbnailColumns = 64
+	thumbnailPreRoll = 0.5 // seconds before the marker
+	thumbnailLength  = 3.0 // seconds after a point marker
+	thumbnailMax     = 10.0
+)
+
+// Printable cue sheet text, in the language of the app. Empty labels fall back to English
+type SheetLabels struct {
+	Time  string
+	Name  string
+	Tags  string
+	Notes string
+	Page  string // "%d / %d"
+}
+
+// What the printable cue sheet shows besides the markers
+type Sheet struct {
+	Title      string
+	Info       [][2]string // track metadata as label and formatted value
+	Filter     string      // description of the applied search and tags, empty without them
+	Labels     SheetLabels
+	Numbers    []int       // numbers of the markers in the full list, they are counted from 1 if it's empty
+	Thumbnails []Thumbnail // one per marker, or none
+}
+
+// Waveform around a marker: min and max of every column, and where the marker is, from 0 to 1
+type Thumbnail struct {
+	Peaks  [][2]float32
+	Marker float64
+}
+
+func (s Sheet) labels() SheetLabels {
+	l := s.Labels
+	fallback := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	fallback(&l.Time, "Time")
+	fallback(&l.Name, "Name")
+	fallback(&l.Tags, "Tags")
+	fallback(&l.Notes, "Notes")
+	fallback(&l.Page, "%d / %d")
+	return l
+}
+
+// Cells of one row of the sheet
+type sheetRow struct {
+	number string
+	time   string
+	name   string
+	tags   string
+	notes  string
+	thumb  *Thumbnail
+}
+
+func sheetRows(doc Document) []sheetRow {
+	rows := make([]sheetRow, len(doc.Markers))
+	for idx, it := range doc.Markers {
+		number := idx + 1
+		if idx < len(doc.Sheet.Numbers) {
+			number = doc.Sheet.Numbers[idx]
+		}
+		rows[idx] = sheetRow{
+			number: strconv.Itoa(number),
+			time:   formatTime(samplesToSeconds(it.Samples, doc.Audio.SampleRate)),
+			name:   it.Name,
+			tags:   strings.Join(it.CategoryTags, ", "),
+			notes:  it.Notes,
+		}
+		if it.IsRegion() {
+			rows[idx].time += " – " + formatTime(samplesToSeconds(it.End, doc.Audio.SampleRate))
+		}
+		if idx < len(doc.Sheet.Thumbnails) && len(doc.Sheet.Thumbnails[idx].Peaks) > 0 {
+			rows[idx].thumb = &doc.Sheet.Thumbnails[idx]
+		}
+	}
+	return rows
+}
+
+func (s Sheet) title(doc Document) string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return doc.AudioName
+}
+
+// Waveforms from half a second before every marker till its end, or a few seconds after a point marker
+func Thumbnails(samples []float32, markers tm.TimeMarkers, sampleRate int) []Thumbnail {
+	thumbs := make([]Thumbnail, len(markers))
+	for idx, it := range markers {
+		start := max(0, it.Samples-int(thumbnailPreRoll*float64(sampleRate)))
+		end := it.Samples + int(thumbnailLength*float64(sampleRate))
+		if it.IsRegion() {
+			end = min(it.End, it.Samples+int(thumbnailMax*float64(sampleRate)))
+		}
+		end = min(end, len(samples))
+		if end-start < thumbnailColumns {
+			continue
+		}
+		thumb := Thumbnail{
+			Peaks:  make([][2]float32, thumbnailColumns),
+			Marker: float64(it.Samples-start) / float64(end-start),
+		}
+		for col := range thumb.Peaks {
+			from := start + (end-start)*col/thumbnailColumns
+			to := start + (end-start)*(col+1)/thumbnailColumns
+			lo, hi := samples[from], samples[from]
+			for _, v := range samples[from:to] {
+				lo, hi = min(lo, v), max(hi, v)
+			}
+			thumb.Peaks[col] = [2]float32{max(lo, -1), min(hi, 1)}
+		}
+		thumbs[idx] = thumb
+	}
+	return thumbs
+}
@@ -0,0 +1,117 @@
+package markerio
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func testSheetDocument(count int) Document {
+	markers := tm.NewTimeMarkers()
+	for idx := range count {
+		marker := newMarker(48000*idx/2, 0, fmt.Sprintf("Сцена %d <b>", idx+1))
+		marker.CategoryTags = append(marker.CategoryTags, "lights", "sound")
+		marker.Notes = strings.Repeat("Wait for the actor to reach the stage mark. ", 4)
+		markers.AttachNewMarker(marker)
+	}
+	return Document{
+		Markers: markers,
+		Target:  Target{Audio: testMeta(), AudioName: "show.wav"},
+		Sheet: Sheet{
+			Info:   [][2]string{{"Length", "01:00"}},
+			Filter: "Tags: lights",
+		},
+	}
+}
+
+func TestThumbnails(t *testing.T) {
+	samples := make([]float32, 48000*4)
+	for idx := 48000; idx < 48000*2; idx++ {
+		samples[idx] = 0.5
+	}
+	markers := tm.NewTimeMarkers()
+	markers.AttachNewMarker(newMarker(48000, 0, "Loud"))
+	markers.AttachNewMarker(newMarker(48000*4, 0, "At the end"))
+	markers.AttachNewMarker(newMarker(48000*5, 0, "Past the audio"))
+	thumbs := Thumbnails(samples, markers, 48000)
+	if len(thumbs) != 3 || thumbs[1].Marker != 1 || thumbs[2].Peaks != nil {
+		t.Fatalf("unexpected thumbnails %+v", thumbs)
+	}
+	// Half a second of silence before the marker out of three and a half seconds
+	if thumbs[0].Marker < 0.14 || thumbs[0].Marker > 0.15 {
+		t.Errorf("marker at %f", thumbs[0].Marker)
+	}
+	if before, loud, after := thumbs[0].Peaks[0], thumbs[0].Peaks[15], thumbs[0].Peaks[40]; before[1] != 0 || loud != [2]float32{0.5, 0.5} || after[1] != 0 {
+		t.Errorf("unexpected peaks %v %v %v", before, loud, after)
+	}
+}
+
+// Every object offset in the cross-reference table points at its object
+func TestPDFStructure(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodePDF(&buf, testSheetDocument(100)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF")
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatal("startxref doesn't point at xref")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	for idx, it := range entries {
+		offset, _ := strconv.Atoi(string(it[1]))
+		if want := fmt.Sprintf("%d 0 obj", idx+1); !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Fatalf("offset of object %d is wrong", idx+1)
+		}
+	}
+	count := regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`).FindSubmatch(out)
+	if pages, _ := strconv.Atoi(string(count[1])); pages < 3 {
+		t.Errorf("100 markers with notes take more than %d pages", pages)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	f, err := newPDFFont("F1", "GoRegular", goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := wrapText(f, "short words here\n\nand "+strings.Repeat("x", 80), 9, 100)
+	if len(lines) < 4 || lines[1] != "" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	for _, it := range lines {
+		if w := f.width(it, 9); w > 100 {
+			t.Errorf("line %q is %f wide", it, w)
+		}
+	}
+}
+
+func TestHTMLSheet(t *testing.T) {
+	doc := testSheetDocument(2)
+	doc.Sheet.Numbers = []int{3, 7}
+	doc.Sheet.Thumbnails = []Thumbnail{{Peaks: [][2]float32{{-0.5, 0.5}, {0, 0}}, Marker: 0.5}}
+	var buf bytes.Buffer
+	if err := EncodeHTML(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, it := range []string{"Сцена 1 &lt;b&gt;", "<td>7</td>", "Tags: lights", "<svg", "<title>show.wav</title>"} {
+		if !strings.Contains(out, it) {
+			t.Errorf("%q is missing", it)
+		}
+	}
+	if strings.Count(out, "<svg") != 1 {
+		t.Error("thumbnail is drawn for the marker without one")
+	}
+}
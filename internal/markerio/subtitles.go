@@ -0,0 +1,95 @@
+package markerio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Cue of a marker at the very end of the audio, which has nothing to run until
+const subtitleMinMs = 1000
+
+type subtitle struct {
+	start, end int // milliseconds
+	lines      []string
+}
+
+// Every marker runs until its own end, or until the next marker, or until the end of the audio
+func subtitles(doc Document) []subtitle {
+	rate := doc.Audio.SampleRate
+	audioEnd := samplesToMs(doc.Audio.MaxMonoSamples(), rate)
+	subs := make([]subtitle, 0, len(doc.Markers))
+	for idx, it := range doc.Markers {
+		sub := subtitle{start: samplesToMs(it.Samples, rate), end: audioEnd}
+		if it.IsRegion() {
+			sub.end = samplesToMs(it.End, rate)
+		} else {
+			for _, next := range doc.Markers[idx+1:] {
+				if next.Samples > it.Samples {
+					sub.end = samplesToMs(next.Samples, rate)
+					break
+				}
+			}
+		}
+		if sub.end <= sub.start {
+			sub.end = sub.start + subtitleMinMs
+		}
+		sub.lines = textLines(it.Name)
+		if doc.WithNotes {
+			sub.lines = append(sub.lines, textLines(it.Notes)...)
+		}
+		if len(sub.lines) == 0 {
+			sub.lines = []string{"#" + strconv.Itoa(idx+1)}
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func samplesToMs(samples, sampleRate int) int {
+	return int((int64(samples)*1000 + int64(sampleRate)/2) / int64(sampleRate))
+}
+
+// Blank lines end a cue in both formats, so they are left out
+func textLines(s string) []string {
+	var lines []string
+	for _, it := range strings.Split(s, "\n") {
+		if it = strings.TrimSpace(it); it != "" {
+			lines = append(lines, it)
+		}
+	}
+	return lines
+}
+
+func formatSubtitleTime(ms int, fraction string) string {
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, fraction, ms%1000)
+}
+
+func EncodeSRT(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	for idx, it := range subtitles(doc) {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n", idx+1, formatSubtitleTime(it.start, ","), formatSubtitleTime(it.end, ","))
+		for _, line := range it.lines {
+			fmt.Fprintln(bw, line)
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func EncodeVTT(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "WEBVTT\n\n")
+	for idx, it := range subtitles(doc) {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n", idx+1, formatSubtitleTime(it.start, "."), formatSubtitleTime(it.end, "."))
+		for _, line := range it.lines {
+			fmt.Fprintln(bw, vttEscaper.Replace(line))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,49 @@
+package markerio
+
+import (
+	"bytes"
+	"testing"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+func testSubtitlesDocument() Document {
+	markers := tm.NewTimeMarkers()
+	intro := newMarker(48000+24, 0, "Intro")
+	intro.Notes = "Lights down\n\n<fade>"
+	markers.AttachNewMarker(intro)
+	markers.AttachNewMarker(newMarker(48000*5, 48000*7, "Solo & choir"))
+	markers.AttachNewMarker(newMarker(48000*5, 0, ""))
+	markers.AttachNewMarker(newMarker(48000*60, 0, "End"))
+	return Document{Markers: markers, Target: Target{Audio: testMeta()}}
+}
+
+func TestSRT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EncodeSRT(&buf, testSubtitlesDocument()); err != nil {
+		t.Fatal(err)
+	}
+	want := "1\n00:00:01,001 --> 00:00:05,000\nIntro\n\n" +
+		"2\n00:00:05,000 --> 00:00:07,000\nSolo & choir\n\n" +
+		"3\n00:00:05,000 --> 00:01:00,000\n#3\n\n" +
+		"4\n00:01:00,000 --> 00:01:01,000\nEnd\n\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestVTTWithNotes(t *testing.T) {
+	doc := testSubtitlesDocument()
+	doc.WithNotes = true
+	doc.Markers = doc.Markers[:2]
+	var buf bytes.Buffer
+	if err := EncodeVTT(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\n\n" +
+		"1\n00:00:01.001 --> 00:00:05.000\nIntro\nLights down\n&lt;fade&gt;\n\n" +
+		"2\n00:00:05.000 --> 00:00:07.000\nSolo &amp; choir\n\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,277 @@
+package markerio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+var ErrNotWAV = errors.New("not a RIFF WAVE file")
+
+type riffChunk struct {
+	id     string
+	data   []byte // empty for the "data" chunk, audio is never kept in memory
+	offset int64  // where the audio of the "data" chunk starts in the file
+	size   int64
+}
+
+// Markers from the "cue " chunk, named by "labl", with "note" as notes and "ltxt" as region length
+func DecodeWAV(r io.Reader, t Target) (tm.TimeMarkers, []RowError, error) {
+	chunks, err := readRIFF(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cues []byte
+	var adtl []byte
+	for _, it := range chunks {
+		switch {
+		case it.id == "cue ":
+			cues = it.data
+		case it.id == "LIST" && len(it.data) >= 4 && string(it.data[:4]) == "adtl":
+			adtl = it.data[4:]
+		}
+	}
+	labels, notes, lengths := parseAdtl(adtl)
+	markers := tm.NewTimeMarkers()
+	var rowErrs []RowError
+	for idx := 0; len(cues) >= 4+(idx+1)*24; idx++ {
+		point := cues[4+idx*24:]
+		id := binary.LittleEndian.Uint32(point)
+		samples := int(binary.LittleEndian.Uint32(point[20:]))
+		if samples > t.Audio.MaxMonoSamples() {
+			rowErrs = append(rowErrs, RowError{Row: idx + 1, Err: fmt.Errorf("%w: %d samples", ErrOutOfTrack, samples)})
+			continue
+		}
+		end := 0
+		if length, ok := lengths[id]; ok && length > 0 {
+			end = min(samples+int(length), t.Audio.MaxMonoSamples())
+		}
+		marker := newMarker(samples, end, labels[id])
+		marker.Notes = notes[id]
+		if !markers.AttachNewMarker(marker) {
+			rowErrs = append(rowErrs, RowError{Row: idx + 1, Err: ErrLimit})
+		}
+	}
+	markers.Sort()
+	return markers, rowErrs, nil
+}
+
+// Reads chunks of a RIFF WAVE file. Audio is skipped, by seeking if "r" can do it
+func readRIFF(r io.Reader) ([]riffChunk, error) {
+	var head [12]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil || string(head[:4]) != "RIFF" || string(head[8:]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+	offset := int64(len(head))
+	var chunks []riffChunk
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, err
+		}
+		offset += int64(len(header))
+		chunk := riffChunk{id: string(header[:4]), offset: offset, size: int64(binary.LittleEndian.Uint32(header[4:]))}
+		padded := chunk.size + chunk.size&1
+		if chunk.id == "data" {
+			if err := skip(r, padded); err != nil {
+				return nil, fmt.Errorf("data chunk: %w", err)
+			}
+		} else {
+			// The size can't be trusted, so the buffer grows only as far as the file really goes
+			data, err := io.ReadAll(io.LimitReader(r, padded))
+			if err != nil {
+				return nil, fmt.Errorf("%q chunk: %w", chunk.id, err)
+			}
+			if int64(len(data)) < padded {
+				return nil, fmt.Errorf("%q chunk: %w", chunk.id, io.ErrUnexpectedEOF)
+			}
+			chunk.data = data[:chunk.size]
+		}
+		offset += padded
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// Seeking doesn't tell when the file ends, so it's checked against the file length
+func skip(r io.Reader, n int64) error {
+	s, ok := r.(io.Seeker)
+	if !ok {
+		_, err := io.CopyN(io.Discard, r, n)
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	cur, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if cur+n > end {
+		return io.ErrUnexpectedEOF
+	}
+	_, err = s.Seek(cur+n, io.SeekStart)
+	return err
+}
+
+func parseAdtl(adtl []byte) (labels, notes map[uint32]string, lengths map[uint32]uint32) {
+	labels, notes, lengths = map[uint32]string{}, map[uint32]string{}, map[uint32]uint32{}
+	for len(adtl) >= 12 {
+		id := string(adtl[:4])
+		size := int(binary.LittleEndian.Uint32(adtl[4:]))
+		if size < 4 || 8+size > len(adtl) {
+			break
+		}
+		body := adtl[8 : 8+size]
+		cueID := binary.LittleEndian.Uint32(body)
+		switch id {
+		case "labl":
+			labels[cueID] = zeroTerminated(body[4:])
+		case "note":
+			notes[cueID] = zeroTerminated(body[4:])
+		case "ltxt":
+			if len(body) >= 8 {
+				lengths[cueID] = binary.LittleEndian.Uint32(body[4:])
+			}
+		}
+		adtl = adtl[min(len(adtl), 8+size+size&1):]
+	}
+	return labels, notes, lengths
+}
+
+func zeroTerminated(b []byte) string {
+	if idx := bytes.IndexByte(b, 0); idx >= 0 {
+		b = b[:idx]
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// Copy of the loaded WAV with its markers replaced by the current ones
+func EncodeWAV(w io.Writer, doc Document) error {
+	src, err := os.Open(doc.AudioPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	chunks, err := readRIFF(src)
+	if err != nil {
+		return err
+	}
+
+	kept := make([]riffChunk, 0, len(chunks)+2)
+	for _, it := range chunks {
+		isAdtl := it.id == "LIST" && len(it.data) >= 4 && string(it.data[:4]) == "adtl"
+		if it.id != "cue " && !isAdtl {
+			kept = append(kept, it)
+		}
+	}
+	kept = append(kept, riffChunk{id: "cue ", data: encodeCuePoints(doc.Markers)})
+	kept = append(kept, riffChunk{id: "LIST", data: encodeAdtl(doc.Markers)})
+
+	riffSize := int64(4)
+	for _, it := range kept {
+		size := it.size
+		if it.id != "data" {
+			size = int64(len(it.data))
+		}
+		riffSize += 8 + size + size&1
+	}
+	if riffSize > 0xffffffff {
+		return errors.New("WAV with markers would exceed 4 GiB")
+	}
+	header := make([]byte, 0, 12)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(riffSize))
+	header = append(header, "WAVE"...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, it := range kept {
+		var data io.Reader = bytes.NewReader(it.data)
+		size := int64(len(it.data))
+		if it.id == "data" {
+			data, size = io.NewSectionReader(src, it.offset, it.size), it.size
+		}
+		if err := writeChunk(w, it.id, data, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChunk(w io.Writer, id string, data io.Reader, size int64) error {
+	header := make([]byte, 0, 8)
+	header = append(header, id...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(size))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(w, data, size); err != nil {
+		return err
+	}
+	if size&1 == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+func encodeCuePoints(markers tm.TimeMarkers) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(markers)))
+	for idx, it := range markers {
+		data = binary.LittleEndian.AppendUint32(data, uint32(idx+1))
+		data = binary.LittleEndian.AppendUint32(data, uint32(it.Samples))
+		data = append(data, "data"...)
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = binary.LittleEndian.AppendUint32(data, uint32(it.Samples))
+	}
+	return data
+}
+
+func encodeAdtl(markers tm.TimeMarkers) []byte {
+	data := []byte("adtl")
+	sub := func(id string, body []byte) {
+		data = append(data, id...)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(body)))
+		data = append(data, body...)
+		if len(body)&1 == 1 {
+			data = append(data, 0)
+		}
+	}
+	text := func(cueID int, s string) []byte {
+		body := binary.LittleEndian.AppendUint32(nil, uint32(cueID))
+		return append(append(body, s...), 0)
+	}
+	for idx, it := range markers {
+		cueID := idx + 1
+		if it.Name != "" {
+			sub("labl", text(cueID, it.Name))
+		}
+		if it.Notes != "" {
+			sub("note", text(cueID, it.Notes))
+		}
+		if it.IsRegion() {
+			body := binary.LittleEndian.AppendUint32(nil, uint32(cueID))
+			body = binary.LittleEndian.AppendUint32(body, uint32(it.End-it.Samples))
+			body = append(body, "rgn "...)
+			body = append(body, make([]byte, 8)...) // country, language, dialect, code page
+			sub("ltxt", body)
+		}
+	}
+	return data
+}
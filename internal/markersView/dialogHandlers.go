@@ -42,6 +42,17 @@ func (m *MarkersView) confirmDeleteAll() {
 	m.ChipsFilter.Purge()
 }
 
+func (m *MarkersView) confirmShift() {
+	if offset := m.shiftDialog.executeConfirm(m.AudioMeta); offset != 0 {
+		m.ShiftMarkers(offset)
+	}
+	m.ResetAlignment()
+}
+func (m *MarkersView) cancelShift() {
+	m.shiftDialog.cancelShift()
+	m.ResetAlignment()
+}
+
 func (m *MarkersView) openMarkerDialog(curMarker *tm.TimeMarker, owner dialogOwner, title string) {
 	if curMarker == nil {
 		return
@@ -106,3 +117,21 @@ func (m *MarkersView) openDeleteAllDialog() {
 	})
 	m.Dialog.Show()
 }
+
+func (m *MarkersView) openShiftDialog() {
+	m.Lg.Info("Markers: open shift dialog")
+	m.dialogOwner = shift
+	m.shiftDialog.prepareForOpening(m.I18n, m.Alignment.Seq)
+	m.Dialog.Basic(m.Th, m.I18n.Markers.MShiftTitle, func(gtx layout.Context) layout.Dimensions {
+		if m.shiftDialog.hasAlignRequest(gtx) {
+			m.AlignWithPrevious(m.AudioMeta.GetSamplesFromSeconds(maxShiftSeconds))
+		}
+		return m.shiftDialog.Layout(gtx, shiftDialogProps{
+			audioMeta: m.AudioMeta,
+			markers:   m.TimeMarkers,
+			alignment: m.Alignment,
+			isBusy:    m.IsChoosing(),
+		})
+	})
+	m.Dialog.Show()
+}
@@ -103,6 +103,16 @@ func (m *MarkersView) Layout(gtx layout.Context) layout.Dimensions {
 				txt.Font.Weight = font.Bold
 				return txt.Layout(gtx)
 			},
+			func(gtx layout.Context) layout.Dimensions {
+				txt := material.Body2(m.Th.Theme, "LUFS")
+				txt.Font.Weight = font.Bold
+				return txt.Layout(gtx)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				txt := material.Body2(m.Th.Theme, "dBTP")
+				txt.Font.Weight = font.Bold
+				return txt.Layout(gtx)
+			},
 			func(gtx layout.Context) layout.Dimensions {
 				if m.tagCl.Clicked(gtx) {
 					m.openTagsFilterDialog()
@@ -161,7 +171,18 @@ func (m *MarkersView) Layout(gtx layout.Context) layout.Dimensions {
 				return layout.Dimensions{}
 			},
 			func(gtx layout.Context) layout.Dimensions {
-				return layout.Dimensions{}
+				if m.shiftCl.Clicked(gtx) {
+					m.openShiftDialog()
+				}
+				if m.shiftCl.Hovered() {
+					common.SetCursor(gtx, pointer.CursorPointer)
+				}
+				return drawClickableIcon(gtx, m.Th, clickableIconProps{
+					icon:     micons.Shift,
+					iconSize: 24,
+					cl:       &m.shiftCl,
+					disabled: m.TimeMarkers.IsEmpty(),
+				})
 			},
 			func(gtx layout.Context) layout.Dimensions {
 				if m.deleteCl.Clicked(gtx) {
@@ -231,6 +252,14 @@ func (m *MarkersView) Layout(gtx layout.Context) layout.Dimensions {
 				txt := material.Body2(m.Th.Theme, formattedSeconds)
 				return txt.Layout(gtx)
 			},
+			func(gtx layout.Context, rowIdx int, curMarker *tm.TimeMarker) layout.Dimensions {
+				txt := material.Body2(m.Th.Theme, m.SectionLoudness(rowIdx).LUFSValueString())
+				return txt.Layout(gtx)
+			},
+			func(gtx layout.Context, rowIdx int, curMarker *tm.TimeMarker) layout.Dimensions {
+				txt := material.Body2(m.Th.Theme, m.SectionLoudness(rowIdx).TruePeakValueString())
+				return txt.Layout(gtx)
+			},
 			func(gtx layout.Context, rowIdx int, curMarker *tm.TimeMarker) layout.Dimensions {
 				gtx.Constraints.Min = image.Point{}
 				tagsArr := curMarker.CategoryTags
@@ -292,7 +321,7 @@ func (m *MarkersView) Layout(gtx layout.Context) layout.Dimensions {
 				})
 			},
 		)
-		m.table.Layout(gtx, m.Th, len(m.TimeMarkers), []int{4, 4, 30, 6, 44, 4, 4, 4})
+		m.table.Layout(gtx, m.Th, len(m.TimeMarkers), []int{4, 4, 26, 6, 6, 6, 36, 4, 4, 4})
 	})
 
 	if isPlaying {
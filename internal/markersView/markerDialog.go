@@ -22,6 +22,8 @@ func newMarkerDialog(tagsDefaultAmount int, th *theme.RepeatTheme, a audio.Audio
 		a:          a,
 		nameField:  &common.Inputable{Focuser: fm},
 		timeField:  &common.Inputable{Focuser: fm},
+		gainField:  &common.Inputable{Focuser: fm},
+		rampField:  &common.Inputable{Focuser: fm},
 		tagsField:  new(common.Comboboxable).WithFocusManager(fm),
 		allTags:    make([]string, tagsDefaultAmount),
 		tags:       make([]string, tagsDefaultAmount),
@@ -39,6 +41,8 @@ type markerDialog struct {
 	tagOptions []string
 	nameField  *common.Inputable
 	timeField  *common.Inputable
+	gainField  *common.Inputable
+	rampField  *common.Inputable
 	tagsField  *common.Comboboxable
 	focuser    *common.FocusManager
 	th         *theme.RepeatTheme
@@ -63,6 +67,14 @@ func (m *markerDialog) prepareForOpening(i18n i18n.State, a audio.AudioMeta, cur
 	m.timeField.SetSanitizer(m.sanitizeTimeInput)
 	m.tags = slices.Clone(curMarker.CategoryTags)
 	m.tagsField.SetText("")
+	m.gainField.SetText("")
+	if curMarker.Gain != nil {
+		m.gainField.SetText(strconv.FormatFloat(*curMarker.Gain, 'f', -1, 64))
+	}
+	m.rampField.SetText("")
+	if curMarker.Ramp > 0 {
+		m.rampField.SetText(strconv.FormatFloat(curMarker.Ramp, 'f', -1, 64))
+	}
 }
 
 func (m *markerDialog) executeConfirm(a audio.AudioMeta) {
@@ -77,10 +89,31 @@ func (m *markerDialog) executeConfirm(a audio.AudioMeta) {
 		m.handleTagsFieldNewChip()
 	}
 	m.TimeMarker.CategoryTags = m.tags
+	m.TimeMarker.Gain, m.TimeMarker.Ramp = m.parseAutomation()
 	m.TimeMarker = nil
 	m.focuser.RequestBlur(nil)
 }
 
+const (
+	minMarkerGain = -60.0
+	maxMarkerGain = 12.0
+	maxMarkerRamp = 60.0
+)
+
+// Empty volume field means the marker doesn't change volume
+func (m *markerDialog) parseAutomation() (*float64, float64) {
+	gain, err := strconv.ParseFloat(m.gainField.Text(), 64)
+	if err != nil {
+		return nil, 0
+	}
+	gain = common.Clamp(minMarkerGain, gain, maxMarkerGain)
+	ramp, err := strconv.ParseFloat(m.rampField.Text(), 64)
+	if err != nil {
+		ramp = 0
+	}
+	return &gain, common.Clamp(0, ramp, maxMarkerRamp)
+}
+
 func (m *markerDialog) cancelCreate() {
 	m.focuser.RequestBlur(nil)
 	m.TimeMarker = nil
@@ -171,6 +204,10 @@ func (m *markerDialog) handleFieldsEvents() {
 		m.focuser.RequestFocus(m.tagsField)
 		return
 	}
+	if m.gainField.HasSubmit() {
+		m.focuser.RequestFocus(m.rampField)
+		return
+	}
 	if m.tagsField.HasSubmit() {
 		m.handleTagsFieldNewChip()
 		return
@@ -192,7 +229,7 @@ func (m *markerDialog) handleFieldsEvents() {
 }
 
 func (m *markerDialog) getCursorType() (pointer.Cursor, bool) {
-	if m.nameField.IsHovered() || m.timeField.IsHovered() || m.tagsField.IsHovered() {
+	if m.nameField.IsHovered() || m.timeField.IsHovered() || m.gainField.IsHovered() || m.rampField.IsHovered() || m.tagsField.IsHovered() {
 		return pointer.CursorText, true
 	}
 	return pointer.CursorDefault, false
@@ -262,6 +299,36 @@ func (m *markerDialog) Layout(gtx layout.Context, totalSeconds float64) layout.D
 					inputDims.Size.Y += gapPx
 					return inputDims
 				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Max.X = fieldW
+					dims := layout.Flex{}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return common.DrawInputField(gtx, m.th, common.InputFieldProps{
+								Base: common.InputFieldBase{
+									LabelText: m.i18n.Markers.MGain,
+								},
+								Filter:      "-1234567890.",
+								Inputable:   m.gainField,
+								MaxLen:      6,
+								Placeholder: "-12",
+							})
+						}),
+						layout.Rigid(layout.Spacer{Width: s.gap}.Layout),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return common.DrawInputField(gtx, m.th, common.InputFieldProps{
+								Base: common.InputFieldBase{
+									LabelText: m.i18n.Markers.MRamp,
+								},
+								Filter:      "1234567890.",
+								Inputable:   m.rampField,
+								MaxLen:      5,
+								Placeholder: "0.5",
+							})
+						}),
+					)
+					dims.Size.Y += gapPx
+					return dims
+				}),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					gtx.Constraints.Max.X = fieldW
 					return common.DrawCombobox(gtx, m.th, common.ComboboxProps{
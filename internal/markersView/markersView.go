@@ -33,6 +33,7 @@ func NewMarkersView(props Props) MarkersView {
 		markerDialog:  newMarkerDialog(chipsDefaultAmount, props.State.Th, props.State.AudioMeta),
 		tagsDialog:    newTagsDialog(chipsDefaultAmount),
 		commentDialog: newCommentDialog(props.State.Th),
+		shiftDialog:   newShiftDialog(props.State.Th),
 	}
 	table := common.NewTable(common.TableProps[*tm.TimeMarker]{
 		Axis: layout.Vertical,
@@ -41,6 +42,8 @@ func NewMarkersView(props Props) MarkersView {
 			layout.Center,
 			layout.W,
 			layout.Center,
+			layout.Center,
+			layout.Center,
 			layout.W,
 			layout.Center,
 			layout.Center,
@@ -50,6 +53,8 @@ func NewMarkersView(props Props) MarkersView {
 			layout.Center,
 			layout.W,
 			layout.Center,
+			layout.Center,
+			layout.Center,
 			layout.W,
 			layout.Center,
 			layout.Center,
@@ -73,6 +78,7 @@ const (
 	edit
 	tagFilter
 	deleteAll
+	shift
 )
 
 // TODO: Remove redundant pointers
@@ -90,10 +96,12 @@ type MarkersView struct {
 	createCl      widget.Clickable
 	disabledCl    widget.Clickable
 	deleteCl      widget.Clickable
+	shiftCl       widget.Clickable
 	dialogOwner   dialogOwner
 	markerDialog  markerDialog
 	tagsDialog    tagsDialog
 	commentDialog commentDialog
+	shiftDialog   shiftDialog
 	hotKeyBuf     []rune
 	pc            playerControllable
 }
@@ -217,6 +225,8 @@ func (m *MarkersView) cancelDialog() {
 		m.commentDialog.cancelComment()
 	case edit:
 		m.markerDialog.cancelEdit()
+	case shift:
+		m.cancelShift()
 	}
 	m.Lg.Info("Markers: cancel dialog")
 	m.Dialog.Hide()
@@ -235,6 +245,8 @@ func (m *MarkersView) confirmDialog() {
 		m.confirmTagFilter()
 	case deleteAll:
 		m.confirmDeleteAll()
+	case shift:
+		m.confirmShift()
 	}
 	m.Lg.Info("Markers: confirm dialog")
 	m.Dialog.Hide()
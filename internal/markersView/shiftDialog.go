@@ -0,0 +1,193 @@
+package markersview
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/spyhere/re-peat/fonts"
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/common"
+	"github.com/spyhere/re-peat/internal/i18n"
+	micons "github.com/spyhere/re-peat/internal/mIcons"
+	"github.com/spyhere/re-peat/internal/state"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+	"github.com/spyhere/re-peat/internal/ui/theme"
+)
+
+const (
+	maxShiftSeconds    = 60.0
+	minAlignConfidence = 0.5 // below that the found offset is not offered
+)
+
+func newShiftDialog(th *theme.RepeatTheme) shiftDialog {
+	fm := &common.FocusManager{}
+	return shiftDialog{
+		offsetField: &common.Inputable{Focuser: fm},
+		focuser:     fm,
+		th:          th,
+	}
+}
+
+type shiftDialog struct {
+	offsetField *common.Inputable
+	focuser     *common.FocusManager
+	alignCl     widget.Clickable
+	alignSeq    int // last alignment result which was put into the field
+	th          *theme.RepeatTheme
+	i18n        i18n.State
+}
+
+type shiftDialogProps struct {
+	audioMeta audio.AudioMeta
+	markers   tm.TimeMarkers
+	alignment state.Alignment
+	isBusy    bool
+}
+
+func (s *shiftDialog) prepareForOpening(i18n i18n.State, alignSeq int) {
+	s.i18n = i18n
+	s.alignSeq = alignSeq
+	s.offsetField.SetText("")
+	s.focuser.RequestFocus(s.offsetField)
+}
+
+// Offset in samples typed by the user or found by alignment
+func (s *shiftDialog) offset(a audio.AudioMeta) int {
+	seconds, err := strconv.ParseFloat(s.offsetField.Text(), 64)
+	if err != nil {
+		return 0
+	}
+	return a.GetSamplesFromSeconds(common.Clamp(-maxShiftSeconds, seconds, maxShiftSeconds))
+}
+
+func (s *shiftDialog) executeConfirm(a audio.AudioMeta) int {
+	offset := s.offset(a)
+	s.focuser.RequestBlur(nil)
+	return offset
+}
+
+func (s *shiftDialog) cancelShift() {
+	s.focuser.RequestBlur(nil)
+}
+
+func (s *shiftDialog) hasAlignRequest(gtx layout.Context) bool {
+	return s.alignCl.Clicked(gtx)
+}
+
+func (s *shiftDialog) applyAlignment(a audio.AudioMeta, al state.Alignment) {
+	if al.Seq == s.alignSeq {
+		return
+	}
+	s.alignSeq = al.Seq
+	if al.Err != nil || al.Result.Confidence < minAlignConfidence {
+		return
+	}
+	seconds := a.GetSecondsFromSamples(al.Result.Offset)
+	s.offsetField.SetText(strconv.FormatFloat(seconds, 'f', 3, 64))
+}
+
+func (s *shiftDialog) alignmentStatus(a audio.AudioMeta, al state.Alignment) string {
+	name := filepath.Base(al.File)
+	switch {
+	case al.IsRunning:
+		return fmt.Sprintf(s.i18n.Markers.MShiftAligning, name)
+	case al.File == "":
+		return ""
+	case al.Err != nil:
+		return fmt.Sprintf(s.i18n.Markers.MShiftFailed, name)
+	case al.Result.Confidence < minAlignConfidence:
+		return fmt.Sprintf(s.i18n.Markers.MShiftNoMatch, name)
+	}
+	seconds := a.GetSecondsFromSamples(al.Result.Offset)
+	return fmt.Sprintf(s.i18n.Markers.MShiftFound, seconds, name, int(math.Round(al.Result.Confidence*100)))
+}
+
+func (s *shiftDialog) getCursorType() (pointer.Cursor, bool) {
+	if s.offsetField.IsHovered() {
+		return pointer.CursorText, true
+	}
+	if s.alignCl.Hovered() {
+		return pointer.CursorPointer, true
+	}
+	return pointer.CursorDefault, false
+}
+
+func (s *shiftDialog) Layout(gtx layout.Context, props shiftDialogProps) layout.Dimensions {
+	if cursor, ok := s.getCursorType(); ok {
+		common.SetCursor(gtx, cursor)
+	}
+	s.applyAlignment(props.audioMeta, props.alignment)
+	st := defaultFieldGroupStyle()
+	inset := layout.Inset{Top: st.fieldsYMargin, Bottom: st.fieldsYMargin, Left: st.fieldsXMargin, Right: st.fieldsXMargin}
+	dims := inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Max.X = gtx.Dp(st.fieldW) / 2
+						return common.DrawInputField(gtx, s.th, common.InputFieldProps{
+							Base: common.InputFieldBase{
+								LabelText: s.i18n.Markers.MShiftOffset,
+							},
+							Filter:      "-1234567890.",
+							Inputable:   s.offsetField,
+							MaxLen:      8,
+							Placeholder: "0.000",
+						})
+					}),
+					layout.Rigid(layout.Spacer{Width: st.gap}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := common.Button(s.th, &s.alignCl, micons.Compare, s.i18n.Markers.MShiftAlign)
+						btn.Disabled = props.isBusy || props.alignment.IsRunning
+						return btn.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				status := s.alignmentStatus(props.audioMeta, props.alignment)
+				if status == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: st.gap / 2}.Layout(gtx, material.Body2(s.th.Theme, status).Layout)
+			}),
+			layout.Rigid(layout.Spacer{Height: st.gap}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return s.layoutPreview(gtx, props)
+			}),
+		)
+	})
+	s.focuser.PlaceScrim(gtx)
+	return dims
+}
+
+// Where every marker lands with the current offset
+func (s *shiftDialog) layoutPreview(gtx layout.Context, props shiftDialogProps) layout.Dimensions {
+	offset := s.offset(props.audioMeta)
+	maxSamples := props.audioMeta.MaxMonoSamples()
+	rows := make([]layout.FlexChild, 0, len(props.markers))
+	for idx, it := range props.markers {
+		shifted, ok := tm.ShiftedSamples(it.Samples, offset, maxSamples)
+		from := common.FormatSecondsPrecise(props.audioMeta.GetSecondsFromSamples(it.Samples))
+		to := common.FormatSecondsPrecise(props.audioMeta.GetSecondsFromSamples(shifted))
+		if !ok {
+			to = s.i18n.Markers.MShiftOutOfTrack
+		}
+		rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			txt := material.Body2(s.th.Theme, fmt.Sprintf("%02d  %s  %s → %s", idx+1, it.Name, from, to))
+			txt.Font = fonts.GoMedium(font.Medium, font.Regular)
+			if !ok {
+				txt.Color = s.th.Palette.Redacted
+			}
+			return txt.Layout(gtx)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
@@ -0,0 +1,266 @@
+package peakcache
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	magic   = "RPK"
+	version = 2
+	ext     = ".peaks"
+)
+
+var ErrCorrupted = errors.New("peak cache file is corrupted")
+
+// Peaks of one resolution, every bin covers "SamplesPerBin" samples
+type Level struct {
+	SamplesPerBin int
+	Peaks         [][][2]float32 // min and max pairs per channel
+	RMS           [][]float32    // per channel
+}
+
+type Peaks struct {
+	Levels []Level
+}
+
+func (p *Peaks) IsEmpty() bool {
+	return p == nil || len(p.Levels) == 0
+}
+
+// Identifies peaks of the audio content decoded with the given format
+type Key struct {
+	Hash       string
+	SampleRate int
+	Channels   int
+	Samples    int
+}
+
+func (k Key) IsEmpty() bool {
+	return k.Hash == ""
+}
+
+func (k Key) fileName() string {
+	return fmt.Sprintf("%s-%d-%d-%d-v%d%s", k.Hash, k.SampleRate, k.Channels, k.Samples, version, ext)
+}
+
+// Content hash of the file at "path"
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Directory of peak files which is kept under "limit" bytes by removing least recently used ones
+type Store struct {
+	dir   string
+	limit int64
+}
+
+func NewStore(dir string, limit int64) *Store {
+	return &Store{dir: dir, limit: limit}
+}
+
+// Store in the user cache directory
+func NewUserStore(limit int64) (*Store, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(filepath.Join(dir, "re-peat", "peaks"), limit), nil
+}
+
+// Returns os.ErrNotExist if there are no peaks for "k". A broken file is removed and counts as missing
+func (s *Store) Load(k Key) (*Peaks, error) {
+	path := filepath.Join(s.dir, k.fileName())
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	p, err := read(bufio.NewReader(f), info.Size())
+	if errors.Is(err, ErrCorrupted) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		f.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Modification time is used to find least recently used files
+	now := time.Now()
+	if err = os.Chtimes(path, now, now); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Store) Save(k Key, p *Peaks) error {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	if err = write(w, p); err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), filepath.Join(s.dir, k.fileName())); err != nil {
+		return err
+	}
+	return s.evict()
+}
+
+func (s *Store) evict() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	var files []os.FileInfo
+	var total int64
+	for _, it := range entries {
+		if it.IsDir() || !strings.HasSuffix(it.Name(), ext) {
+			continue
+		}
+		info, err := it.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+	slices.SortFunc(files, func(a, b os.FileInfo) int {
+		return a.ModTime().Compare(b.ModTime())
+	})
+	for _, it := range files {
+		if total <= s.limit {
+			break
+		}
+		if err = os.Remove(filepath.Join(s.dir, it.Name())); err != nil {
+			return err
+		}
+		total -= it.Size()
+	}
+	return nil
+}
+
+type header struct {
+	Magic   [3]byte
+	Version uint8
+	Levels  uint32
+}
+
+type levelHeader struct {
+	SamplesPerBin uint32
+	Channels      uint32
+	Bins          uint32
+}
+
+func write(w io.Writer, p *Peaks) error {
+	h := header{Version: version, Levels: uint32(len(p.Levels))}
+	copy(h.Magic[:], magic)
+	if err := binary.Write(w, binary.LittleEndian, h); err != nil {
+		return err
+	}
+	for _, lvl := range p.Levels {
+		if len(lvl.Peaks) != len(lvl.RMS) {
+			return fmt.Errorf("level %d has %d peak and %d RMS channels", lvl.SamplesPerBin, len(lvl.Peaks), len(lvl.RMS))
+		}
+		bins := 0
+		if len(lvl.Peaks) > 0 {
+			bins = len(lvl.Peaks[0])
+		}
+		lh := levelHeader{SamplesPerBin: uint32(lvl.SamplesPerBin), Channels: uint32(len(lvl.Peaks)), Bins: uint32(bins)}
+		if err := binary.Write(w, binary.LittleEndian, lh); err != nil {
+			return err
+		}
+		for ch := range lvl.Peaks {
+			if len(lvl.Peaks[ch]) != bins || len(lvl.RMS[ch]) != bins {
+				return fmt.Errorf("level %d has channels of different length", lvl.SamplesPerBin)
+			}
+			if err := binary.Write(w, binary.LittleEndian, lvl.Peaks[ch]); err != nil {
+				return err
+			}
+			if err := binary.Write(w, binary.LittleEndian, lvl.RMS[ch]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Upper bound for a single level, protects from allocating garbage sizes
+const maxBins = 1 << 28
+
+// "size" is the length of the file, sizes in headers are checked against it before anything is allocated
+func read(r io.Reader, size int64) (*Peaks, error) {
+	var h header
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, err
+	}
+	if string(h.Magic[:]) != magic || h.Version != version {
+		return nil, ErrCorrupted
+	}
+	left := size - int64(binary.Size(h))
+	p := &Peaks{Levels: make([]Level, 0, min(h.Levels, 64))}
+	for range h.Levels {
+		var lh levelHeader
+		if err := binary.Read(r, binary.LittleEndian, &lh); err != nil {
+			return nil, err
+		}
+		left -= int64(binary.Size(lh))
+		// Min and max peak and RMS are 12 bytes per bin
+		levelSize := int64(lh.Bins) * int64(lh.Channels) * 12
+		if lh.SamplesPerBin == 0 || lh.Channels > 16 || lh.Bins > maxBins || levelSize > left {
+			return nil, ErrCorrupted
+		}
+		left -= levelSize
+		lvl := Level{
+			SamplesPerBin: int(lh.SamplesPerBin),
+			Peaks:         make([][][2]float32, lh.Channels),
+			RMS:           make([][]float32, lh.Channels),
+		}
+		for ch := range lh.Channels {
+			lvl.Peaks[ch] = make([][2]float32, lh.Bins)
+			lvl.RMS[ch] = make([]float32, lh.Bins)
+			if err := binary.Read(r, binary.LittleEndian, lvl.Peaks[ch]); err != nil {
+				return nil, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, lvl.RMS[ch]); err != nil {
+				return nil, err
+			}
+		}
+		p.Levels = append(p.Levels, lvl)
+	}
+	return p, nil
+}
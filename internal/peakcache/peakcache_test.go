@@ -0,0 +1,108 @@
+package peakcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testPeaks(bins int) *Peaks {
+	lvl := Level{SamplesPerBin: 64, Peaks: make([][][2]float32, 2), RMS: make([][]float32, 2)}
+	for ch := range 2 {
+		for i := range bins {
+			v := float32(i) / float32(bins)
+			lvl.Peaks[ch] = append(lvl.Peaks[ch], [2]float32{-v, v})
+			lvl.RMS[ch] = append(lvl.RMS[ch], v/2)
+		}
+	}
+	return &Peaks{Levels: []Level{lvl}}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	s := NewStore(t.TempDir(), 1<<20)
+	k := Key{Hash: "abc", SampleRate: 44100, Channels: 2, Samples: 1000}
+	want := testPeaks(100)
+	if err := s.Save(k, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Load(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded peaks differ from saved ones")
+	}
+	k.SampleRate = 48000
+	if _, err = s.Load(k); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v for another format, want not exist", err)
+	}
+}
+
+func TestStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 1<<20)
+	old, recent := Key{Hash: "old"}, Key{Hash: "recent"}
+	if err := s.Save(old, testPeaks(100)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, old.fileName()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Room for exactly one file
+	s.limit = info.Size()
+	past := time.Now().Add(-time.Hour)
+	if err = os.Chtimes(filepath.Join(dir, old.fileName()), past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Save(recent, testPeaks(100)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Load(old); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v for the old file, want it evicted", err)
+	}
+	if _, err = s.Load(recent); err != nil {
+		t.Errorf("recent file: %v", err)
+	}
+}
+
+func TestReadRejectsGarbage(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 1<<20)
+	k := Key{Hash: "garbage"}
+	if err := os.WriteFile(filepath.Join(dir, k.fileName()), []byte("not a peak file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(k); err == nil {
+		t.Error("expected an error for garbage")
+	}
+}
+
+func TestLoadTreatsWrongSizesAsMiss(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 1<<20)
+	k := Key{Hash: "short"}
+	if err := s.Save(k, testPeaks(100)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, k.fileName())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bins of the first level, right after the file header, samples per bin and channels
+	binary.LittleEndian.PutUint32(data[16:], maxBins)
+	if err = os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Load(k); !errors.Is(err, os.ErrNotExist) || !errors.Is(err, ErrCorrupted) {
+		t.Errorf("got %v, want a miss for the corrupted file", err)
+	}
+	if _, err = os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("corrupted file is kept: %v", err)
+	}
+}
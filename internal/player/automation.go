@@ -0,0 +1,85 @@
+package player
+
+import (
+	"slices"
+
+	"github.com/gopxl/beep/effects"
+)
+
+// Volume starts ramping to "Gain" (dB) at "Samples" and reaches it after "Ramp" samples
+type AutomationPoint struct {
+	Samples int
+	Gain    float64
+	Ramp    int
+}
+
+// Volume automation in dB over source samples, "Base" is applied everywhere (track normalisation)
+type Envelope struct {
+	Base   float64
+	Points []AutomationPoint // sorted by Samples
+}
+
+func (e Envelope) IsFlat() bool {
+	return e.Base == 0 && len(e.Points) == 0
+}
+
+func (e Envelope) Equal(other Envelope) bool {
+	return e.Base == other.Base && slices.Equal(e.Points, other.Points)
+}
+
+// Gain in dB at the given position
+func (e Envelope) At(samples int) float64 {
+	var level float64
+	for idx, it := range e.Points {
+		if it.Samples > samples {
+			break
+		}
+		// Level at the end of this point's window, the next ramp starts from it
+		end := samples
+		if idx+1 < len(e.Points) && e.Points[idx+1].Samples <= samples {
+			end = e.Points[idx+1].Samples
+		}
+		elapsed := end - it.Samples
+		if elapsed >= it.Ramp {
+			level = it.Gain
+		} else {
+			level += (it.Gain - level) * float64(elapsed) / float64(it.Ramp)
+		}
+	}
+	return e.Base + level
+}
+
+// Volume is updated this often while streaming, in samples
+const automationStep = 64
+
+// Ramps "volume" along the envelope, "position" reports source position of the next streamed sample
+type automation struct {
+	volume   *effects.Volume
+	envelope Envelope
+	position func() int
+}
+
+func (a *automation) Stream(samples [][2]float64) (n int, ok bool) {
+	if a.envelope.IsFlat() {
+		a.volume.Volume = 0
+		return a.volume.Stream(samples)
+	}
+	pos := a.position()
+	for n < len(samples) {
+		to := min(n+automationStep, len(samples))
+		a.volume.Volume = a.envelope.At(pos+n) / 20
+		streamed, ok := a.volume.Stream(samples[n:to])
+		n += streamed
+		if !ok {
+			return n, n > 0
+		}
+		if streamed < to-(n-streamed) {
+			break
+		}
+	}
+	return n, true
+}
+
+func (a *automation) Err() error {
+	return a.volume.Err()
+}
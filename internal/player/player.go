@@ -9,35 +9,51 @@ import (
 	"github.com/gopxl/beep/effects"
 	"github.com/gopxl/beep/speaker"
 	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/playhead"
 )
 
-const defaultSampleRate = 44100
+// Output settings can't be changed after the speaker is initialised, except for resample quality
+type Options struct {
+	SampleRate      int
+	BufferSize      time.Duration
+	ResampleQuality int
+}
 
-func NewPlayer() *Player {
-	speaker.Init(defaultSampleRate, beep.SampleRate(defaultSampleRate).N(time.Second/10))
+func NewPlayer(opts Options) *Player {
+	output := beep.Format{
+		SampleRate:  beep.SampleRate(opts.SampleRate),
+		NumChannels: 2,
+		Precision:   2,
+	}
+	speaker.Init(output.SampleRate, output.SampleRate.N(opts.BufferSize))
 	return &Player{
-		format: beep.Format{
-			SampleRate:  defaultSampleRate,
-			NumChannels: 2,
-		},
+		output:  output,
+		buffer:  opts.BufferSize,
+		quality: opts.ResampleQuality,
 	}
 }
 
 type Player struct {
-	streamer  beep.StreamSeekCloser
-	format    beep.Format
-	ctrl      *beep.Ctrl
-	volume    *effects.Volume
-	isPlaying bool
-	eof       bool
+	streamer   beep.StreamSeekCloser
+	format     beep.Format // source format, positions are counted in it
+	output     beep.Format
+	buffer     time.Duration
+	quality    int
+	meter      playhead.LatencyMeter
+	ctrl       *beep.Ctrl
+	automation *automation
+	volume     *effects.Volume
+	isPlaying  bool
+	eof        bool
+
+	lastPos     int
+	lastAdvance time.Time
 }
 
-func (p *Player) attachStreamer(str beep.Streamer, f beep.Format) {
-	if f.SampleRate != p.format.SampleRate {
-		resampled := beep.Resample(4, f.SampleRate, p.format.SampleRate, p.volume)
-		str = resampled
+func (p *Player) attachStreamer(str beep.Streamer) {
+	if p.format.SampleRate != p.output.SampleRate {
+		str = beep.Resample(p.quality, p.format.SampleRate, p.output.SampleRate, str)
 	}
-	p.format = f
 	speaker.Play(beep.Seq(str, beep.Callback(func() {
 		p.eof = true
 		p.isPlaying = false
@@ -45,24 +61,35 @@ func (p *Player) attachStreamer(str beep.Streamer, f beep.Format) {
 }
 
 func (p *Player) SetAudio(f *os.File) (audio.AudioMeta, error) {
-	if p.streamer != nil {
-		p.streamer.Close()
-	}
-
 	streamer, format, err := audio.Decode(f)
 	if err != nil {
 		return audio.AudioMeta{}, err
 	}
+	// Previous audio stays playable if the new one can't be decoded
+	if p.streamer != nil {
+		speaker.Clear()
+		p.streamer.Close()
+	}
 
 	p.streamer = streamer
 	p.ctrl = &beep.Ctrl{Streamer: streamer, Paused: true}
+	p.automation = &automation{
+		volume: &effects.Volume{
+			Streamer: p.ctrl,
+			Base:     10,
+		},
+		position: streamer.Position,
+	}
 	p.volume = &effects.Volume{
-		Streamer: p.ctrl,
+		Streamer: p.automation,
 		Base:     2,
 		Volume:   0,
 		Silent:   false,
 	}
-	p.attachStreamer(p.volume, format)
+	p.format = format
+	p.meter = playhead.NewLatencyMeter(int(format.SampleRate), p.buffer)
+	p.eof = false
+	p.attachStreamer(p.volume)
 	return audio.NewAudioMeta(int(format.SampleRate), format.NumChannels, streamer.Len()), nil
 }
 
@@ -85,6 +112,54 @@ func (p *Player) SetVolume(volume float64) {
 	p.volume.Volume = math.Log2(v)
 }
 
+// Automation applied on top of the user volume
+func (p *Player) SetEnvelope(e Envelope) {
+	if p.automation == nil {
+		return
+	}
+	speaker.Lock()
+	defer speaker.Unlock()
+	p.automation.envelope = e
+}
+
+func (p *Player) GetEnvelope() Envelope {
+	if p.automation == nil {
+		return Envelope{}
+	}
+	speaker.Lock()
+	defer speaker.Unlock()
+	return p.automation.envelope
+}
+
+func (p *Player) OutputSampleRate() int {
+	return int(p.output.SampleRate)
+}
+
+func (p *Player) BufferSize() time.Duration {
+	return p.buffer
+}
+
+// Measured time between decoding and hearing a sample
+func (p *Player) Latency() time.Duration {
+	speaker.Lock()
+	defer speaker.Unlock()
+	return p.meter.Latency()
+}
+
+// Rebuilds the output chain with the new quality, the position stays as the source streamer is the same
+func (p *Player) SetResampleQuality(quality int) {
+	if p.quality == quality {
+		return
+	}
+	p.quality = quality
+	// After EOF the chain is attached again on the next "Set" or "Search"
+	if p.streamer == nil || p.eof {
+		return
+	}
+	speaker.Clear()
+	p.attachStreamer(p.volume)
+}
+
 func (p *Player) Play() {
 	if p.eof {
 		return
@@ -93,6 +168,7 @@ func (p *Player) Play() {
 	defer speaker.Unlock()
 	p.ctrl.Paused = false
 	p.isPlaying = true
+	p.meter.Start(p.streamer.Position(), time.Now())
 }
 
 func (p *Player) Pause() {
@@ -103,6 +179,7 @@ func (p *Player) Pause() {
 	defer speaker.Unlock()
 	p.ctrl.Paused = true
 	p.isPlaying = false
+	p.meter.Stop()
 }
 
 func (p *Player) Toggle() {
@@ -126,7 +203,7 @@ func (p *Player) Set(samples int) (int, error) {
 		p.ctrl.Paused = true
 		speaker.Unlock()
 		p.eof = false
-		p.attachStreamer(p.volume, p.format)
+		p.attachStreamer(p.volume)
 	}
 	speaker.Lock()
 	defer speaker.Unlock()
@@ -134,6 +211,7 @@ func (p *Player) Set(samples int) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	p.restartMeter()
 	return p.streamer.Position(), nil
 }
 
@@ -143,24 +221,58 @@ func (p *Player) Search(seconds float32) (int, error) {
 		p.ctrl.Paused = true
 		speaker.Unlock()
 		p.eof = false
-		p.attachStreamer(p.volume, p.format)
+		p.attachStreamer(p.volume)
 	}
 	speaker.Lock()
 	defer speaker.Unlock()
 	dur := time.Duration(seconds * float32(time.Second))
-	samplesN := p.format.SampleRate.N(dur)
+	samplesN := playhead.SamplesFromDuration(dur, int(p.format.SampleRate))
 	if err := p.streamer.Seek(samplesN); err != nil {
 		return 0, err
 	}
+	p.restartMeter()
 	return p.streamer.Position(), nil
 }
 
+// Position not moving for this long while playing means the output device is gone or hung
+const stallTimeout = time.Second
+
+// Should be polled regularly while playing. The speaker can't be re-initialised on another device,
+// so output always follows the system default device and this is the only way to notice it's gone.
+// Safe to call from any goroutine: the state is guarded by the speaker lock like the rest of playback.
+func (p *Player) IsStalled(now time.Time) bool {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if !p.isPlaying {
+		p.lastAdvance = time.Time{}
+		return false
+	}
+	pos := p.streamer.Position()
+	if pos != p.lastPos || p.lastAdvance.IsZero() {
+		p.lastPos = pos
+		p.lastAdvance = now
+		return false
+	}
+	return now.Sub(p.lastAdvance) > stallTimeout
+}
+
+// Position that is being heard right now, output latency is subtracted while playing
 func (p *Player) GetReadAmount() int {
 	speaker.Lock()
 	defer speaker.Unlock()
-	return p.streamer.Position()
+	if !p.isPlaying {
+		return p.streamer.Position()
+	}
+	return p.meter.Compensate(p.streamer.Position(), time.Now())
+}
+
+// Should be called under speaker lock
+func (p *Player) restartMeter() {
+	if p.isPlaying {
+		p.meter.Start(p.streamer.Position(), time.Now())
+	}
 }
 
 func (p *Player) GetCurrentSecond() float64 {
-	return math.Round(float64(p.format.SampleRate.D(p.GetReadAmount())/time.Second)*100) / 100
+	return playhead.SecondsFromSamples(p.GetReadAmount(), int(p.format.SampleRate))
 }
@@ -0,0 +1,25 @@
+package playhead
+
+import (
+	"math"
+	"time"
+)
+
+// Player positions are always counted in source samples, output rate doesn't affect them
+
+func DurationFromSamples(samples, sampleRate int) time.Duration {
+	if sampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(samples) * time.Second / time.Duration(sampleRate)
+}
+
+// Rounded to the nearest sample, so it survives a round trip through DurationFromSamples
+func SamplesFromDuration(d time.Duration, sampleRate int) int {
+	return int((d*time.Duration(sampleRate) + time.Second/2) / time.Second)
+}
+
+// Rounded to hundredths
+func SecondsFromSamples(samples, sampleRate int) float64 {
+	return math.Round(DurationFromSamples(samples, sampleRate).Seconds()*100) / 100
+}
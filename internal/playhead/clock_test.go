@@ -0,0 +1,75 @@
+package playhead
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplesFromDurationUsesSourceRate(t *testing.T) {
+	cases := []struct {
+		name       string
+		d          time.Duration
+		sampleRate int
+		want       int
+	}{
+		{"44.1k one second", time.Second, 44100, 44100},
+		{"48k one second", time.Second, 48000, 48000},
+		{"44.1k one and a half", 1500 * time.Millisecond, 44100, 66150},
+		{"48k one and a half", 1500 * time.Millisecond, 48000, 72000},
+		{"96k ten minutes", 10 * time.Minute, 96000, 57600000},
+		{"zero", 0, 48000, 0},
+	}
+	for _, c := range cases {
+		if got := SamplesFromDuration(c.d, c.sampleRate); got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDurationFromSamples(t *testing.T) {
+	cases := []struct {
+		samples    int
+		sampleRate int
+		want       time.Duration
+	}{
+		{44100, 44100, time.Second},
+		{44100, 48000, 918750 * time.Microsecond},
+		{72000, 48000, 1500 * time.Millisecond},
+		{100, 0, 0},
+	}
+	for _, c := range cases {
+		if got := DurationFromSamples(c.samples, c.sampleRate); got != c.want {
+			t.Errorf("%d samples at %d Hz: got %v, want %v", c.samples, c.sampleRate, got, c.want)
+		}
+	}
+}
+
+// The same position must map to the same source sample no matter what rate was used before
+func TestRoundTripAcrossRates(t *testing.T) {
+	for _, rate := range []int{22050, 44100, 48000, 96000} {
+		for _, samples := range []int{0, 1, rate - 1, rate, rate*60 + 17} {
+			d := DurationFromSamples(samples, rate)
+			if got := SamplesFromDuration(d, rate); got != samples {
+				t.Errorf("%d Hz: %d samples -> %v -> %d samples", rate, samples, d, got)
+			}
+		}
+	}
+}
+
+func TestSecondsFromSamples(t *testing.T) {
+	cases := []struct {
+		samples    int
+		sampleRate int
+		want       float64
+	}{
+		{66150, 44100, 1.5},
+		{66150, 48000, 1.38},
+		{48000 * 61, 48000, 61},
+		{12345, 44100, 0.28},
+	}
+	for _, c := range cases {
+		if got := SecondsFromSamples(c.samples, c.sampleRate); got != c.want {
+			t.Errorf("%d samples at %d Hz: got %v, want %v", c.samples, c.sampleRate, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package playhead
+
+import "time"
+
+// Share of a new measurement in the estimate, output pulls samples in chunks so raw values saw-tooth
+const latencySmoothing = 0.05
+
+// Estimates how far the decoded position runs ahead of what is heard.
+// Output pulls samples faster than real time until its buffer is filled, that excess is the latency.
+type LatencyMeter struct {
+	sampleRate int
+	max        int // buffer size in source samples, latency can't be bigger
+	startPos   int
+	startTime  time.Time
+	latency    float64
+	running    bool
+}
+
+func NewLatencyMeter(sampleRate int, buffer time.Duration) LatencyMeter {
+	return LatencyMeter{
+		sampleRate: sampleRate,
+		max:        SamplesFromDuration(buffer, sampleRate),
+	}
+}
+
+// Should be called when playback starts or jumps, the estimate is kept between runs
+func (m *LatencyMeter) Start(pos int, now time.Time) {
+	m.startPos = pos
+	m.startTime = now
+	m.running = true
+}
+
+func (m *LatencyMeter) Stop() {
+	m.running = false
+}
+
+// Audible position for the decoded one
+func (m *LatencyMeter) Compensate(pos int, now time.Time) int {
+	if !m.running {
+		return pos
+	}
+	expected := SamplesFromDuration(now.Sub(m.startTime), m.sampleRate)
+	ahead := min(max(pos-m.startPos-expected, 0), m.max)
+	if m.latency == 0 {
+		m.latency = float64(ahead)
+	} else {
+		m.latency += (float64(ahead) - m.latency) * latencySmoothing
+	}
+	return max(pos-int(m.latency), m.startPos)
+}
+
+func (m *LatencyMeter) Latency() time.Duration {
+	return DurationFromSamples(int(m.latency), m.sampleRate)
+}
@@ -0,0 +1,47 @@
+package playhead
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyMeterCompensatesBufferedSamples(t *testing.T) {
+	const rate = 48000
+	buffered := SamplesFromDuration(100*time.Millisecond, rate)
+	m := NewLatencyMeter(rate, 100*time.Millisecond)
+	start := time.Unix(0, 0)
+	m.Start(rate, start)
+	// Output keeps its buffer full, so the decoder is always "buffered" samples ahead of real time
+	for ms := 0; ms <= 2000; ms += 10 {
+		now := start.Add(time.Duration(ms) * time.Millisecond)
+		decoded := rate + SamplesFromDuration(now.Sub(start), rate) + buffered
+		got := m.Compensate(decoded, now)
+		want := decoded - buffered
+		if diff := got - want; diff < -1 || diff > 1 {
+			t.Fatalf("at %d ms: got %d, want %d", ms, got, want)
+		}
+	}
+}
+
+func TestLatencyMeterNeverGoesBeforeStart(t *testing.T) {
+	m := NewLatencyMeter(44100, 100*time.Millisecond)
+	start := time.Unix(0, 0)
+	m.Start(1000, start)
+	if got := m.Compensate(1000+4410, start); got != 1000 {
+		t.Errorf("got %d, want playback start 1000", got)
+	}
+}
+
+func TestLatencyMeterIsLimitedByBuffer(t *testing.T) {
+	m := NewLatencyMeter(44100, 50*time.Millisecond)
+	start := time.Unix(0, 0)
+	m.Start(0, start)
+	now := start.Add(time.Second)
+	if got, want := m.Compensate(44100+44100, now), 44100+44100-2205; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	m.Stop()
+	if got := m.Compensate(12345, now); got != 12345 {
+		t.Errorf("stopped meter changed position: got %d", got)
+	}
+}
@@ -1,14 +1,21 @@
 package projectview
 
 import (
+	"fmt"
 	"image"
+	"strconv"
+	"strings"
 
 	"gioui.org/font"
 	"gioui.org/layout"
 	"gioui.org/text"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/spyhere/re-peat/internal/audio"
 	"github.com/spyhere/re-peat/internal/common"
+	"github.com/spyhere/re-peat/internal/i18n"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
 	"github.com/spyhere/re-peat/internal/ui/theme"
 )
 
@@ -75,3 +82,71 @@ func (i infoListStyle) layout(gtx layout.Context, rows ...layout.FlexChild) layo
 	})
 	return dims
 }
+
+type settingItem struct {
+	cl   *widget.Clickable
+	text string
+}
+
+// Row of clickable chips centered horizontally, "bottomM" is the margin from the bottom edge
+func settingsComp(gtx layout.Context, th *theme.RepeatTheme, bottomM int, items ...settingItem) {
+	children := make([]layout.FlexChild, 0, len(items)*2)
+	for idx, it := range items {
+		if idx > 0 {
+			children = append(children, layout.Rigid(layout.Spacer{Width: settingsGap}.Layout))
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return common.DrawChip(gtx, th, common.ChipProps{
+				Text:     it.text,
+				Selected: true,
+				HideIcon: true,
+				Cl:       it.cl,
+			})
+		}))
+	}
+	rowM, rowDims := common.MakeMacro(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Point{}
+		return layout.Flex{}.Layout(gtx, children...)
+	})
+	pos := image.Pt((gtx.Constraints.Max.X-rowDims.Size.X)/2, gtx.Constraints.Max.Y-bottomM-rowDims.Size.Y)
+	common.OffsetBy(gtx, pos, func(gtx layout.Context) {
+		rowM.Add(gtx.Ops)
+	})
+}
+
+const markerTextMax = 40 // runes, chips are one line high
+
+// Time, name and tags of a marker in one line. Volume automation and notes are added when they are what differs
+func describeMarker(i18n i18n.ProjectView, a audio.AudioMeta, m *tm.TimeMarker, fields tm.ChangeFields) string {
+	parts := make([]string, 0, 5)
+	position := common.FormatSecondsPrecise(a.GetSecondsFromSamples(m.Samples))
+	if m.IsRegion() {
+		position += " – " + common.FormatSecondsPrecise(a.GetSecondsFromSamples(m.End))
+	}
+	parts = append(parts, position)
+	if fields.Has(tm.FieldAutomation) {
+		parts = append(parts, describeAutomation(i18n, m))
+	}
+	if m.Name != "" {
+		parts = append(parts, m.Name)
+	}
+	if len(m.CategoryTags) > 0 {
+		parts = append(parts, "#"+strings.Join(m.CategoryTags, " #"))
+	}
+	if fields.Has(tm.FieldNotes) {
+		note, _, _ := strings.Cut(m.Notes, "\n")
+		parts = append(parts, "“"+note+"”")
+	}
+	text := []rune(strings.Join(parts, "  "))
+	if len(text) > markerTextMax {
+		return string(text[:markerTextMax-1]) + "…"
+	}
+	return string(text)
+}
+
+func describeAutomation(i18n i18n.ProjectView, m *tm.TimeMarker) string {
+	if m.Gain == nil {
+		return i18n.DiffNoGain
+	}
+	return fmt.Sprintf(i18n.DiffGain, strconv.FormatFloat(*m.Gain, 'f', -1, 64), strconv.FormatFloat(m.Ramp, 'f', -1, 64))
+}
@@ -0,0 +1,119 @@
+package projectview
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/common"
+	"github.com/spyhere/re-peat/internal/i18n"
+	micons "github.com/spyhere/re-peat/internal/mIcons"
+	"github.com/spyhere/re-peat/internal/state"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+	"github.com/spyhere/re-peat/internal/ui/theme"
+)
+
+const diffRowsGap unit.Dp = 10
+
+// Changes of the current markers against the saved file, every one can be reverted
+type diffDialog struct {
+	diff      *state.MarkersDiff
+	revertCls []widget.Clickable
+}
+
+func (d *diffDialog) prepare(diff *state.MarkersDiff) {
+	d.diff = diff
+	d.revertCls = make([]widget.Clickable, len(diff.Changes))
+}
+
+// Index of the change to revert, -1 if none was clicked
+func (d *diffDialog) update(gtx layout.Context) int {
+	reverted := -1
+	for idx := range d.revertCls {
+		if d.revertCls[idx].Clicked(gtx) {
+			reverted = idx
+		}
+		if d.revertCls[idx].Hovered() {
+			common.SetCursor(gtx, pointer.CursorPointer)
+		}
+	}
+	return reverted
+}
+
+// What happened to the marker, like "Moved by +1.250 s, Renamed"
+func describeChange(i18n i18n.State, a audio.AudioMeta, c tm.Change) string {
+	p := i18n.Project
+	switch c.Kind {
+	case tm.ChangeAdded:
+		return p.DiffAdded
+	case tm.ChangeRemoved:
+		return p.DiffRemoved
+	}
+	parts := make([]string, 0, 6)
+	if c.Fields.Has(tm.FieldTime) {
+		parts = append(parts, fmt.Sprintf(p.DiffMoved, fmt.Sprintf("%+.3f", a.GetSecondsFromSamples(c.Delta()))))
+	}
+	if c.Fields.Has(tm.FieldEnd) {
+		parts = append(parts, p.DiffResized)
+	}
+	if c.Fields.Has(tm.FieldName) {
+		parts = append(parts, p.DiffRenamed)
+	}
+	if c.Fields.Has(tm.FieldTags) {
+		parts = append(parts, p.DiffRetagged)
+	}
+	if c.Fields.Has(tm.FieldNotes) {
+		parts = append(parts, p.DiffNotes)
+	}
+	if c.Fields.Has(tm.FieldAutomation) {
+		parts = append(parts, p.DiffAutomation)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *diffDialog) Layout(gtx layout.Context, th *theme.RepeatTheme, i18n i18n.State, a audio.AudioMeta, revert func(idx int)) layout.Dimensions {
+	if idx := d.update(gtx); idx >= 0 {
+		revert(idx)
+		d.prepare(d.diff)
+	}
+	if len(d.diff.Changes) == 0 {
+		return material.Body2(th.Theme, fmt.Sprintf(i18n.Project.DiffNothing, d.diff.File)).Layout(gtx)
+	}
+	rows := make([]layout.FlexChild, 0, len(d.diff.Changes)*2)
+	for idx, it := range d.diff.Changes {
+		if idx > 0 {
+			rows = append(rows, layout.Rigid(layout.Spacer{Height: diffRowsGap}.Layout))
+		}
+		lines := []string{}
+		if it.Old != nil {
+			lines = append(lines, describeMarker(i18n.Project, a, it.Old, it.Fields))
+		}
+		if it.New != nil {
+			lines = append(lines, "→ "+describeMarker(i18n.Project, a, it.New, it.Fields))
+		}
+		cl := &d.revertCls[idx]
+		rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							txt := material.Body2(th.Theme, describeChange(i18n, a, it))
+							txt.Font.Weight = font.Bold
+							return txt.Layout(gtx)
+						}),
+						layout.Rigid(material.Body2(th.Theme, strings.Join(lines, "\n")).Layout),
+					)
+				}),
+				layout.Rigid(layout.Spacer{Width: diffRowsGap}.Layout),
+				layout.Rigid(common.Button(th, cl, micons.Replay, i18n.Project.DiffRevert).Layout),
+			)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
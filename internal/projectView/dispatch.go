@@ -11,15 +11,50 @@ func (pv *ProjectView) dispatch(gtx layout.Context) {
 		pv.AudioLoad()
 	}
 
+	if pv.bundleSaveCl.Clicked(gtx) {
+		pv.bundleSaveCl = widget.Clickable{}
+		pv.BundleSave()
+	}
+
 	if pv.markersLoadCl.Clicked(gtx) {
 		pv.markersLoadCl = widget.Clickable{}
 		pv.MarkersLoad()
 	}
 
+	if pv.markersMergeCl.Clicked(gtx) {
+		pv.markersMergeCl = widget.Clickable{}
+		pv.MarkersMergeLoad()
+	}
+
+	if pv.markersDiffCl.Clicked(gtx) {
+		pv.openDiffDialog()
+	}
+
 	if pv.markersSaveCl.Clicked(gtx) {
 		pv.MarkersSave()
 	}
 
+	if pv.outputRateCl.Clicked(gtx) {
+		pv.CycleOutputSampleRate()
+	}
+
+	if pv.resamplerCl.Clicked(gtx) {
+		pv.CycleResampleQuality()
+	}
+
+	if pv.bufferCl.Clicked(gtx) {
+		pv.CycleBufferSize()
+	}
+
+	if pv.markersImportCl.Clicked(gtx) {
+		pv.markersImportCl = widget.Clickable{}
+		pv.MarkersImport()
+	}
+
+	if pv.markersExportCl.Clicked(gtx) {
+		pv.openExportDialog()
+	}
+
 	if pv.markersSaveAsCl.Clicked(gtx) {
 		pv.markersSaveAsCl = widget.Clickable{}
 		pv.MarkersSaveAs()
@@ -0,0 +1,191 @@
+package projectview
+
+import (
+	"fmt"
+	"strconv"
+
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"github.com/spyhere/re-peat/internal/common"
+	"github.com/spyhere/re-peat/internal/i18n"
+	"github.com/spyhere/re-peat/internal/markerio"
+	"github.com/spyhere/re-peat/internal/state"
+	"github.com/spyhere/re-peat/internal/ui/theme"
+)
+
+const (
+	exportOptionsGap unit.Dp = 12
+	tempoFieldW      unit.Dp = 135
+)
+
+const (
+	optionFiltered = iota
+	optionThumbnails
+	optionNotes
+	optionsCount
+)
+
+func newExportDialog() exportDialog {
+	fm := &common.FocusManager{}
+	return exportDialog{
+		tempoField: &common.Inputable{Focuser: fm},
+		focuser:    fm,
+	}
+}
+
+// Lets the user pick one of the export formats and what goes into it
+type exportDialog struct {
+	exporters   []markerio.Exporter
+	chips       []common.FilterChip
+	selected    int
+	canFilter   bool
+	optionChips [optionsCount]common.FilterChip
+	tempoField  *common.Inputable
+	focuser     *common.FocusManager
+	i18n        i18n.State
+}
+
+// Formats depend on the loaded audio, the last choice is kept if it's still offered.
+// Filtering is offered while the search or tags hide some, but not all markers.
+// Tempo field starts with the project tempo, empty if it's not set
+func (e *exportDialog) prepare(exporters []markerio.Exporter, canFilter bool, optionTexts [optionsCount]string, tempo float64, i18n i18n.State) {
+	selected := ""
+	if e.selected < len(e.exporters) {
+		selected = e.exporters[e.selected].Name
+	}
+	e.exporters, e.selected = exporters, 0
+	e.chips = make([]common.FilterChip, len(exporters))
+	for idx, it := range exporters {
+		e.chips[idx].Text = fmt.Sprintf("%s (%s)", it.Name, it.Ext)
+		if it.Name == selected {
+			e.selected = idx
+		}
+	}
+	e.canFilter = canFilter
+	for idx, it := range optionTexts {
+		e.optionChips[idx].Text = it
+	}
+	e.optionChips[optionFiltered].Selected = canFilter
+	e.tempoField.SetText("")
+	if tempo > 0 {
+		e.tempoField.SetText(strconv.FormatFloat(tempo, 'f', -1, 64))
+	}
+	e.i18n = i18n
+}
+
+func (e *exportDialog) close() {
+	e.focuser.RequestBlur(nil)
+}
+
+func (e *exportDialog) exporter() markerio.Exporter {
+	return e.exporters[e.selected]
+}
+
+func (e *exportDialog) options() state.ExportOptions {
+	return state.ExportOptions{
+		Filtered:   e.canFilter && e.optionChips[optionFiltered].Selected,
+		Thumbnails: e.exporter().Thumbnails && e.optionChips[optionThumbnails].Selected,
+		Notes:      e.exporter().Notes && e.optionChips[optionNotes].Selected,
+		Tempo:      e.tempo(),
+	}
+}
+
+// Typed tempo if the format uses it, 0 otherwise
+func (e *exportDialog) tempo() float64 {
+	if !e.exporter().Tempo {
+		return 0
+	}
+	bpm, err := strconv.ParseFloat(e.tempoField.Text(), 64)
+	if err != nil || bpm < markerio.MinTempo || bpm > markerio.MaxTempo {
+		return 0
+	}
+	return bpm
+}
+
+func (e *exportDialog) update(gtx layout.Context) {
+	for idx := range e.chips {
+		if e.chips[idx].Cl.Clicked(gtx) {
+			e.selected = idx
+		}
+		if e.chips[idx].Cl.Hovered() {
+			common.SetCursor(gtx, pointer.CursorPointer)
+		}
+	}
+	for idx := range e.chips {
+		e.chips[idx].Selected = idx == e.selected
+	}
+	for idx := range e.optionChips {
+		it := &e.optionChips[idx]
+		if it.Cl.Clicked(gtx) {
+			it.Selected = !it.Selected
+		}
+		if it.Cl.Hovered() {
+			common.SetCursor(gtx, pointer.CursorPointer)
+		}
+	}
+	if e.tempoField.IsHovered() {
+		common.SetCursor(gtx, pointer.CursorText)
+	}
+}
+
+func (e *exportDialog) isOptionShown(option int) bool {
+	switch option {
+	case optionFiltered:
+		return e.canFilter
+	case optionThumbnails:
+		return e.exporter().Thumbnails
+	case optionNotes:
+		return e.exporter().Notes
+	}
+	return false
+}
+
+func (e *exportDialog) Layout(gtx layout.Context, th *theme.RepeatTheme) layout.Dimensions {
+	e.update(gtx)
+	options := make([]layout.FlexChild, 0, optionsCount*2)
+	for idx := range e.optionChips {
+		if !e.isOptionShown(idx) {
+			continue
+		}
+		if len(options) > 0 {
+			options = append(options, layout.Rigid(layout.Spacer{Width: exportOptionsGap}.Layout))
+		}
+		it := &e.optionChips[idx]
+		options = append(options, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return common.DrawChip(gtx, th, common.ChipProps{Text: it.Text, Selected: it.Selected, Cl: &it.Cl})
+		}))
+	}
+	dims := layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return common.DrawChipsFilter(gtx, th, e.chips)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(options) == 0 {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Top: exportOptionsGap}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{}.Layout(gtx, options...)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !e.exporter().Tempo {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Top: exportOptionsGap}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(tempoFieldW)
+				return common.DrawInputField(gtx, th, common.InputFieldProps{
+					Base: common.InputFieldBase{
+						LabelText: e.i18n.Project.ExportTempo,
+					},
+					Filter:      "1234567890.",
+					Inputable:   e.tempoField,
+					MaxLen:      6,
+					Placeholder: strconv.Itoa(markerio.DefaultTempo),
+				})
+			})
+		}),
+	)
+	e.focuser.PlaceScrim(gtx)
+	return dims
+}
@@ -18,11 +18,13 @@ const (
 	columnW             = 30.0
 	columnWMax  unit.Dp = 400
 	columnH             = 38.0
-	columnHMax  unit.Dp = 270
+	columnHMax  unit.Dp = 320
 	titleCtaGap unit.Dp = 30
 	CtaListGap  unit.Dp = 20
 	ListCtaGap  unit.Dp = 20
 	CtaGap      unit.Dp = 20
+	settingsGap unit.Dp = 8
+	settingsMB  unit.Dp = 30
 )
 
 func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
@@ -30,6 +32,7 @@ func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
 		gtx = gtx.Disabled()
 	}
 	pv.dispatch(gtx)
+	pv.dialogUpdate()
 
 	common.DrawBackground(gtx, pv.Th.Palette.Project.Bg)
 	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -60,9 +63,24 @@ func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							gtx.Constraints.Min.X = tableW
 							return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-								btn := material.IconButton(pv.Th.Theme, &pv.audioLoadCl, micons.Folder, "Load")
-								btn.Background = pv.Th.Palette.Project.LoadButtonBg
-								return btn.Layout(gtx)
+								return layout.Flex{}.Layout(gtx,
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										btn := material.IconButton(pv.Th.Theme, &pv.audioLoadCl, micons.Folder, "Load")
+										btn.Background = pv.Th.Palette.Project.LoadButtonBg
+										return btn.Layout(gtx)
+									}),
+									layout.Rigid(layout.Spacer{Width: CtaGap}.Layout),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										bundleCl := &pv.bundleSaveCl
+										if !pv.HasAudioLoaded() {
+											bundleCl = &pv.disabledCl
+											gtx = gtx.Disabled()
+										}
+										btn := material.IconButton(pv.Th.Theme, bundleCl, micons.Archive, pv.I18n.Project.BundleSave)
+										btn.Background = pv.Th.Palette.Project.LoadButtonBg
+										return btn.Layout(gtx)
+									}),
+								)
 							})
 						}),
 						layout.Rigid(layout.Spacer{Height: CtaListGap}.Layout),
@@ -77,6 +95,9 @@ func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
 								drawInfoRow(pv.Th, pv.I18n.Generic.Size, fMeta.SizeString()),
 								drawInfoRow(pv.Th, pv.I18n.Generic.AudioChannels, aMeta.ChannelsString(pv.I18n)),
 								drawInfoRow(pv.Th, pv.I18n.Generic.SampleRate, aMeta.SampleRateString()),
+								drawInfoRow(pv.Th, pv.I18n.Generic.Loudness, pv.Loudness.Track.LUFSString()),
+								drawInfoRow(pv.Th, pv.I18n.Generic.TruePeak, pv.Loudness.Track.TruePeakString()),
+								drawInfoRow(pv.Th, pv.I18n.Generic.Normalisation, pv.TrackGainString()),
 								drawInfoRow(pv.Th, pv.I18n.Generic.Modified, fMeta.UpdatedAtString()),
 							)
 						}),
@@ -106,23 +127,56 @@ func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 								gtx.Constraints.Min.X = tableW
 								return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-									cl := &pv.markersLoadCl
+									loadCl, mergeCl, importCl := &pv.markersLoadCl, &pv.markersMergeCl, &pv.markersImportCl
 									if !pv.HasAudioLoaded() {
-										cl = &pv.disabledCl
+										loadCl, mergeCl, importCl = &pv.disabledCl, &pv.disabledCl, &pv.disabledCl
 										gtx = gtx.Disabled()
 									}
-									btn := material.IconButton(pv.Th.Theme, cl, micons.Folder, "Load")
-									btn.Background = pv.Th.Palette.Project.LoadButtonBg
-									return btn.Layout(gtx)
+									diffCl := &pv.markersDiffCl
+									if !pv.HasMarkersLoaded() {
+										diffCl = &pv.disabledCl
+									}
+									return layout.Flex{}.Layout(gtx,
+										layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+											btn := material.IconButton(pv.Th.Theme, loadCl, micons.Folder, "Load")
+											btn.Background = pv.Th.Palette.Project.LoadButtonBg
+											return btn.Layout(gtx)
+										}),
+										layout.Rigid(layout.Spacer{Width: CtaGap}.Layout),
+										layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+											btn := material.IconButton(pv.Th.Theme, mergeCl, micons.Merge, pv.I18n.Project.ImportMerge)
+											btn.Background = pv.Th.Palette.Project.LoadButtonBg
+											return btn.Layout(gtx)
+										}),
+										layout.Rigid(layout.Spacer{Width: CtaGap}.Layout),
+										layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+											btn := material.IconButton(pv.Th.Theme, importCl, micons.Import, pv.I18n.Project.Import)
+											btn.Background = pv.Th.Palette.Project.LoadButtonBg
+											return btn.Layout(gtx)
+										}),
+										layout.Rigid(layout.Spacer{Width: CtaGap}.Layout),
+										layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+											if !pv.HasMarkersLoaded() {
+												gtx = gtx.Disabled()
+											}
+											btn := material.IconButton(pv.Th.Theme, diffCl, micons.Difference, pv.I18n.Project.Compare)
+											btn.Background = pv.Th.Palette.Project.LoadButtonBg
+											return btn.Layout(gtx)
+										}),
+									)
 								})
 							}),
 							layout.Rigid(layout.Spacer{Height: CtaListGap}.Layout),
 							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 								gtx.Constraints.Min.X = tableW
 								gtx.Constraints.Max.Y = gtx.Constraints.Min.Y
+								quietest, loudest := pv.LoudnessExtremes()
 								return infoList(pv.Th, pv.MFileMeta.Name).layout(gtx,
 									drawInfoRow(pv.Th, pv.I18n.Generic.Amount, pv.MarkersMeta.AmountString()),
 									drawInfoRow(pv.Th, pv.I18n.Generic.WithComments, pv.MarkersMeta.WithCommentsString()),
+									drawInfoRow(pv.Th, pv.I18n.Generic.QuietestSection, pv.getSectionLoudnessString(quietest)),
+									drawInfoRow(pv.Th, pv.I18n.Generic.LoudestSection, pv.getSectionLoudnessString(loudest)),
+									drawInfoRow(pv.Th, pv.I18n.Generic.Tempo, pv.TempoString()),
 									drawInfoRow(pv.Th, pv.I18n.Generic.Size, pv.MFileMeta.SizeString()),
 									drawInfoRow(pv.Th, pv.I18n.Generic.Modified, pv.MFileMeta.UpdatedAtString()),
 								)
@@ -150,6 +204,15 @@ func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
 										btnStyle.Disabled = pv.TimeMarkers.IsEmpty()
 										return btnStyle.Layout(gtx)
 									}),
+									layout.Rigid(layout.Spacer{Width: CtaGap}.Layout),
+									layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+										btnStyle := common.Button(pv.Th, &pv.markersExportCl, micons.Export, pv.I18n.Project.Export)
+										btnStyle.WExpanded = true
+										btnStyle.Bg = btnBg
+										btnStyle.Fg = btnFg
+										btnStyle.Disabled = pv.TimeMarkers.IsEmpty()
+										return btnStyle.Layout(gtx)
+									}),
 								)
 							}),
 						)
@@ -160,7 +223,15 @@ func (pv *ProjectView) Layout(gtx layout.Context) layout.Dimensions {
 		)
 	})
 
-	if pv.audioLoadCl.Hovered() || pv.markersLoadCl.Hovered() || pv.markersSaveCl.Hovered() || pv.markersSaveAsCl.Hovered() {
+	settingsComp(gtx, pv.Th, gtx.Dp(settingsMB),
+		settingItem{cl: &pv.outputRateCl, text: pv.getOutputRateLabel()},
+		settingItem{cl: &pv.resamplerCl, text: pv.getResamplerLabel()},
+		settingItem{cl: &pv.bufferCl, text: pv.getBufferLabel()},
+	)
+
+	if pv.audioLoadCl.Hovered() || pv.bundleSaveCl.Hovered() || pv.markersLoadCl.Hovered() || pv.markersSaveCl.Hovered() || pv.markersSaveAsCl.Hovered() ||
+		pv.markersImportCl.Hovered() || pv.markersExportCl.Hovered() || pv.markersMergeCl.Hovered() || pv.markersDiffCl.Hovered() ||
+		pv.outputRateCl.Hovered() || pv.resamplerCl.Hovered() || pv.bufferCl.Hovered() {
 		common.SetCursor(gtx, pointer.CursorPointer)
 	}
 	return layout.Dimensions{}
@@ -0,0 +1,126 @@
+package projectview
+
+import (
+	"fmt"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/common"
+	"github.com/spyhere/re-peat/internal/i18n"
+	"github.com/spyhere/re-peat/internal/state"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+	"github.com/spyhere/re-peat/internal/ui/theme"
+)
+
+const (
+	mergeColumnsGap unit.Dp = 12
+	mergeRowsGap    unit.Dp = 6
+)
+
+// Current markers and the ones of another file side by side, the user picks one side of every change
+type mergeDialog struct {
+	rows []mergeRow
+}
+
+type mergeRow struct {
+	currentCl widget.Clickable
+	fileCl    widget.Clickable
+	take      bool // the side of the file is picked
+}
+
+// Markers only in the file are taken, everything else keeps the current side
+func (m *mergeDialog) prepare(merge *state.MarkersMerge) {
+	m.rows = make([]mergeRow, len(merge.Changes))
+	for idx, it := range merge.Changes {
+		m.rows[idx].take = it.Kind == tm.ChangeAdded
+	}
+}
+
+func (m *mergeDialog) taken() []bool {
+	take := make([]bool, len(m.rows))
+	for idx, it := range m.rows {
+		take[idx] = it.take
+	}
+	return take
+}
+
+func (m *mergeDialog) update(gtx layout.Context) {
+	for idx := range m.rows {
+		it := &m.rows[idx]
+		if it.currentCl.Clicked(gtx) {
+			it.take = false
+		}
+		if it.fileCl.Clicked(gtx) {
+			it.take = true
+		}
+		if it.currentCl.Hovered() || it.fileCl.Hovered() {
+			common.SetCursor(gtx, pointer.CursorPointer)
+		}
+	}
+}
+
+func (m *mergeDialog) summary(i18n i18n.State, merge *state.MarkersMerge) string {
+	var added, removed, modified int
+	for _, it := range merge.Changes {
+		switch it.Kind {
+		case tm.ChangeAdded:
+			added++
+		case tm.ChangeRemoved:
+			removed++
+		case tm.ChangeModified:
+			modified++
+		}
+	}
+	return fmt.Sprintf(i18n.Project.MergeSummary, added, removed, modified)
+}
+
+func (m *mergeDialog) Layout(gtx layout.Context, th *theme.RepeatTheme, i18n i18n.State, a audio.AudioMeta, merge *state.MarkersMerge) layout.Dimensions {
+	m.update(gtx)
+	side := func(marker *tm.TimeMarker, fields tm.ChangeFields, selected bool, cl *widget.Clickable) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			text := i18n.Project.MergeAbsent
+			if marker != nil {
+				text = describeMarker(i18n.Project, a, marker, fields)
+			}
+			return common.DrawChip(gtx, th, common.ChipProps{Text: text, Selected: selected, Cl: cl})
+		})
+	}
+	header := func(text string) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			txt := material.Body2(th.Theme, text)
+			txt.Font.Weight = font.Bold
+			return txt.Layout(gtx)
+		})
+	}
+	rows := make([]layout.FlexChild, 0, len(merge.Changes)*2+3)
+	rows = append(rows,
+		layout.Rigid(material.Body2(th.Theme, m.summary(i18n, merge)).Layout),
+		layout.Rigid(layout.Spacer{Height: mergeColumnsGap}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{}.Layout(gtx,
+				header(i18n.Project.MergeCurrent),
+				layout.Rigid(layout.Spacer{Width: mergeColumnsGap}.Layout),
+				header(fmt.Sprintf(i18n.Project.MergeFrom, merge.File)),
+			)
+		}),
+	)
+	for idx, it := range merge.Changes {
+		row := &m.rows[idx]
+		rows = append(rows,
+			layout.Rigid(layout.Spacer{Height: mergeRowsGap}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{}.Layout(gtx,
+					side(it.Old, it.Fields, !row.take, &row.currentCl),
+					layout.Rigid(layout.Spacer{Width: mergeColumnsGap}.Layout),
+					side(it.New, it.Fields, row.take, &row.fileCl),
+				)
+			}),
+		)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
@@ -1,7 +1,11 @@
 package projectview
 
 import (
+	"fmt"
+
+	"gioui.org/layout"
 	"gioui.org/widget"
+	"github.com/spyhere/re-peat/internal/configs"
 	"github.com/spyhere/re-peat/internal/state"
 )
 
@@ -11,19 +15,166 @@ type Props struct {
 
 func NewProjectView(props Props) ProjectView {
 	return ProjectView{
-		AppState: props.State,
+		AppState:     props.State,
+		exportDialog: newExportDialog(),
 	}
 }
 
 type ProjectView struct {
 	*state.AppState
 	audioLoadCl     widget.Clickable
+	bundleSaveCl    widget.Clickable
 	markersLoadCl   widget.Clickable
 	markersSaveCl   widget.Clickable
 	markersSaveAsCl widget.Clickable
+	markersImportCl widget.Clickable
+	markersExportCl widget.Clickable
+	markersMergeCl  widget.Clickable
+	markersDiffCl   widget.Clickable
 	disabledCl      widget.Clickable
+	outputRateCl    widget.Clickable
+	resamplerCl     widget.Clickable
+	bufferCl        widget.Clickable
+	exportDialog    exportDialog
+	isExportOpen    bool
+	mergeDialog     mergeDialog
+	isMergeOpen     bool
+	diffDialog      diffDialog
+	isDiffOpen      bool
 }
 
 func (p *ProjectView) isDisabled() bool {
 	return p.AppState.IsLoading() || p.AppState.IsChoosing()
 }
+
+// Marker number with loudness of the section it starts, empty if there is nothing to show
+func (p *ProjectView) getSectionLoudnessString(idx int) string {
+	if idx < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%02d: %s", idx+1, p.SectionLoudness(idx).LUFSString())
+}
+
+func (p *ProjectView) getOutputRateLabel() string {
+	i18n := p.I18n.Project
+	label := fmt.Sprintf("%s: %.1f kHz", i18n.OutputRate, float64(p.Cfgs.GetOutputSampleRate())/1000)
+	if p.IsOutputRestartNeeded() {
+		label += " (" + i18n.AfterRestart + ")"
+	}
+	return label
+}
+
+func (p *ProjectView) getBufferLabel() string {
+	i18n := p.I18n.Project
+	label := fmt.Sprintf("%s: %d ms", i18n.Buffer, p.Cfgs.GetBufferMs())
+	if p.Player != nil && p.Player.Latency() > 0 {
+		label += fmt.Sprintf(", %s %d ms", i18n.Latency, p.Player.Latency().Milliseconds())
+	}
+	if p.IsBufferRestartNeeded() {
+		label += " (" + i18n.AfterRestart + ")"
+	}
+	return label
+}
+
+func (p *ProjectView) getResamplerLabel() string {
+	i18n := p.I18n.Project
+	quality := i18n.ResamplerStandard
+	switch p.Cfgs.GetResampleQuality() {
+	case configs.ResampleQualities[0]:
+		quality = i18n.ResamplerFast
+	case configs.ResampleQualities[2]:
+		quality = i18n.ResamplerHigh
+	}
+	return i18n.Resampler + ": " + quality
+}
+
+func (p *ProjectView) openExportDialog() {
+	p.Lg.Info("Project: open export dialog")
+	p.isExportOpen = true
+	shown, total := p.ShownMarkersCount(), len(p.TimeMarkers)
+	i18n := p.I18n.Project
+	p.exportDialog.prepare(p.MarkersExporters(), p.IsMarkersFiltered() && shown > 0, [optionsCount]string{
+		optionFiltered:   fmt.Sprintf(i18n.ExportFiltered, shown, total),
+		optionThumbnails: i18n.ExportThumbnails,
+		optionNotes:      i18n.ExportNotes,
+	}, p.Tempo, p.I18n)
+	p.Dialog.Basic(p.Th, i18n.ExportTitle, func(gtx layout.Context) layout.Dimensions {
+		return p.exportDialog.Layout(gtx, p.Th)
+	})
+	p.Dialog.SetLabels(p.I18n.Generic.Cancel, p.I18n.Project.Export)
+	p.Dialog.Show()
+}
+
+// Opens when markers of another file are loaded for merging
+func (p *ProjectView) openMergeDialog(merge *state.MarkersMerge) {
+	p.Lg.Info("Project: open merge dialog")
+	p.isMergeOpen = true
+	p.mergeDialog.prepare(merge)
+	p.Dialog.Basic(p.Th, p.I18n.Project.MergeTitle, func(gtx layout.Context) layout.Dimensions {
+		return p.mergeDialog.Layout(gtx, p.Th, p.I18n, p.AudioMeta, merge)
+	})
+	p.Dialog.SetLabels(p.I18n.Generic.Cancel, p.I18n.Project.ImportMerge)
+	p.Dialog.Show()
+}
+
+func (p *ProjectView) openDiffDialog() {
+	diff, ok := p.MarkersDiffLoad()
+	if !ok {
+		return
+	}
+	p.Lg.Info("Project: open diff dialog")
+	p.isDiffOpen = true
+	p.diffDialog.prepare(diff)
+	p.Dialog.Basic(p.Th, fmt.Sprintf(p.I18n.Project.DiffTitle, diff.File), func(gtx layout.Context) layout.Dimensions {
+		return p.diffDialog.Layout(gtx, p.Th, p.I18n, p.AudioMeta, func(idx int) {
+			p.MarkersDiffRevert(diff, idx)
+		})
+	})
+	p.Dialog.SetLabels("", p.I18n.Generic.Ok)
+	p.Dialog.CancelProps.IsHidden = true
+	p.Dialog.Show()
+}
+
+func (p *ProjectView) dialogUpdate() {
+	if p.isDiffOpen {
+		if p.Dialog.IsCanceled() || p.Dialog.IsConfirmed() {
+			p.Dialog.Hide()
+			p.isDiffOpen = false
+		}
+		return
+	}
+	if merge := p.PendingMerge(); merge != nil && !p.isMergeOpen {
+		p.openMergeDialog(merge)
+	}
+	if p.isMergeOpen {
+		p.mergeDialogUpdate()
+		return
+	}
+	if !p.isExportOpen {
+		return
+	}
+	if p.Dialog.IsCanceled() {
+		p.exportDialog.close()
+		p.Dialog.Hide()
+		p.isExportOpen = false
+	}
+	if p.Dialog.IsConfirmed() {
+		p.exportDialog.close()
+		p.Dialog.Hide()
+		p.isExportOpen = false
+		p.MarkersExport(p.exportDialog.exporter(), p.exportDialog.options())
+	}
+}
+
+func (p *ProjectView) mergeDialogUpdate() {
+	if p.Dialog.IsCanceled() {
+		p.Dialog.Hide()
+		p.isMergeOpen = false
+		p.MarkersMergeCancel()
+	}
+	if p.Dialog.IsConfirmed() {
+		p.Dialog.Hide()
+		p.isMergeOpen = false
+		p.MarkersMergeResolve(p.mergeDialog.taken())
+	}
+}
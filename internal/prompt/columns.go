@@ -0,0 +1,95 @@
+package prompt
+
+import (
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+	"github.com/spyhere/re-peat/internal/common"
+)
+
+const columnsRowGap unit.Dp = 12
+
+// Column chips for every field, the first chip of each row means "not mapped"
+type columnsMap struct {
+	fields []string
+	chips  [][]common.FilterChip
+}
+
+func newColumnsMap(fields, columns []string, mapping []int) *columnsMap {
+	c := &columnsMap{fields: fields, chips: make([][]common.FilterChip, len(fields))}
+	for idx := range fields {
+		row := make([]common.FilterChip, len(columns)+1)
+		row[0].Text = "—"
+		for col, name := range columns {
+			row[col+1].Text = name
+		}
+		row[mapping[idx]+1].Selected = true
+		c.chips[idx] = row
+	}
+	return c
+}
+
+func (c *columnsMap) mapping() []int {
+	mapping := make([]int, len(c.fields))
+	for idx, row := range c.chips {
+		for col, chip := range row {
+			if chip.Selected {
+				mapping[idx] = col - 1
+			}
+		}
+	}
+	return mapping
+}
+
+func (c *columnsMap) update(gtx layout.Context) {
+	for _, row := range c.chips {
+		for col := range row {
+			if row[col].Cl.Hovered() {
+				common.SetCursor(gtx, pointer.CursorPointer)
+			}
+			if !row[col].Cl.Clicked(gtx) {
+				continue
+			}
+			for other := range row {
+				row[other].Selected = other == col
+			}
+		}
+	}
+}
+
+func (p *Prompter) layoutColumns(gtx layout.Context, c *columnsMap) layout.Dimensions {
+	c.update(gtx)
+	rows := make([]layout.FlexChild, 0, len(c.fields)*2)
+	for idx, field := range c.fields {
+		rows = append(rows,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				txt := material.Body2(p.th.Theme, field)
+				txt.Font.Weight = font.Bold
+				return txt.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				dims := common.DrawChipsFilter(gtx, p.th, c.chips[idx])
+				dims.Size.Y += gtx.Dp(columnsRowGap)
+				return dims
+			}),
+		)
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+}
+
+// Lets the user pick a column for every field, -1 stands for no column.
+// "mapping" is the initial choice. This blocks goroutine
+func (p *Prompter) MapColumns(title string, fields, columns []string, mapping []int) ([]int, bool) {
+	p.working <- struct{}{}
+	c := newColumnsMap(fields, columns, mapping)
+	p.Dialog.Basic(p.th, title, func(gtx layout.Context) layout.Dimensions {
+		return p.layoutColumns(gtx, c)
+	})
+	p.Dialog.DisableScrim()
+	p.Dialog.SetLabels(p.i18n.Generic.Cancel, p.i18n.Generic.Ok)
+	p.Dialog.Show()
+	ok := <-p.ch
+	return c.mapping(), ok
+}
@@ -44,6 +44,18 @@ func (p *Prompter) Ask(title, question string) bool {
 	return <-p.ch
 }
 
+// Same as Ask, but buttons are labeled with the answers. This blocks goroutine
+func (p *Prompter) Choose(title, question, no, yes string) bool {
+	p.working <- struct{}{}
+	p.Dialog.Basic(p.th, title, func(gtx layout.Context) layout.Dimensions {
+		return material.Body2(p.th.Theme, question).Layout(gtx)
+	})
+	p.Dialog.DisableScrim()
+	p.Dialog.SetLabels(no, yes)
+	p.Dialog.Show()
+	return <-p.ch
+}
+
 // This blocks goroutine
 func (p *Prompter) Tell(title, msg string) bool {
 	p.working <- struct{}{}
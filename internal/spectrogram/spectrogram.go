@@ -0,0 +1,131 @@
+package spectrogram
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/spyhere/re-peat/internal/dsp"
+)
+
+const (
+	FFTSize = 1024
+	Bins    = FFTSize / 2
+	MinHop  = 512  // samples between columns on the most detailed level
+	maxCols = 1024 // the coarsest level should not have more columns than this
+	dbRange = 90.0 // dynamic range that is mapped into 0..255
+)
+
+// Full scale sine peaks at FFTSize/4 with Hann window, so it becomes 0 dB
+var dbOffset = 20 * math.Log10(FFTSize/4)
+
+// Column of quantized magnitudes, 0 is -dbRange dB and lower, 255 is 0 dB and higher
+type Column [Bins]uint8
+
+// One level of STFT where every column covers "Hop" samples
+type Level struct {
+	Hop     int
+	Columns []Column
+}
+
+// Levels are sorted from the most detailed one to the coarsest
+type Spectrogram struct {
+	SampleRate int
+	Levels     []Level
+}
+
+func (s Spectrogram) IsEmpty() bool {
+	return len(s.Levels) == 0
+}
+
+// Picks the coarsest level that still has at least 1 column per px
+func (s Spectrogram) GetLevel(samplesPerPx float32) Level {
+	lvl := s.Levels[0]
+	for _, it := range s.Levels {
+		if float32(it.Hop) > samplesPerPx {
+			break
+		}
+		lvl = it
+	}
+	return lvl
+}
+
+// Bin index for a given frequency
+func (s Spectrogram) BinFromFreq(freq float64) int {
+	bin := int(freq * FFTSize / float64(s.SampleRate))
+	return min(max(bin, 0), Bins-1)
+}
+
+func (s Spectrogram) Nyquist() float64 {
+	return float64(s.SampleRate) / 2
+}
+
+// Computes STFT of the samples using all CPU cores and builds coarser levels by max pooling.
+// Returns an empty spectrogram and context error if it was canceled.
+func Build(ctx context.Context, samples []float32, sampleRate int) (Spectrogram, error) {
+	colsN := len(samples) / MinHop
+	finest := Level{Hop: MinHop, Columns: make([]Column, colsN)}
+
+	workers := runtime.NumCPU()
+	chunk := (colsN + workers - 1) / workers
+	window := hannWindow(FFTSize)
+	var wg sync.WaitGroup
+	for w := range workers {
+		from, to := w*chunk, min((w+1)*chunk, colsN)
+		if from >= to {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			computeColumns(ctx, samples, window, finest.Columns, from, to)
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return Spectrogram{}, err
+	}
+
+	s := Spectrogram{SampleRate: sampleRate, Levels: []Level{finest}}
+	for prev := finest; len(prev.Columns) > maxCols; {
+		next := Level{Hop: prev.Hop * 2, Columns: make([]Column, len(prev.Columns)/2)}
+		for i := range next.Columns {
+			a, b := &prev.Columns[i*2], &prev.Columns[i*2+1]
+			for bin := range Bins {
+				next.Columns[i][bin] = max(a[bin], b[bin])
+			}
+		}
+		s.Levels = append(s.Levels, next)
+		prev = next
+	}
+	return s, nil
+}
+
+func computeColumns(ctx context.Context, samples []float32, window []float64, cols []Column, from, to int) {
+	f := dsp.NewFFT(FFTSize)
+	buf := make([]complex128, FFTSize)
+	for col := from; col < to; col++ {
+		if col%256 == 0 && ctx.Err() != nil {
+			return
+		}
+		// Column is centered on its hop
+		start := col*MinHop + MinHop/2 - FFTSize/2
+		for i := range buf {
+			idx := start + i
+			var v float64
+			if idx >= 0 && idx < len(samples) {
+				v = float64(samples[idx]) * window[i]
+			}
+			buf[i] = complex(v, 0)
+		}
+		f.Transform(buf)
+		for bin := range Bins {
+			re, im := real(buf[bin]), imag(buf[bin])
+			power := re*re + im*im
+			db := 10*math.Log10(power+1e-20) - dbOffset
+			v := (db + dbRange) / dbRange * 255
+			cols[col][bin] = uint8(min(max(v, 0), 255))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package spectrogram
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+const testRate = 48000
+
+func build(t *testing.T, samples []float32) Spectrogram {
+	t.Helper()
+	s, err := Build(context.Background(), samples, testRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLevelSizes(t *testing.T) {
+	s := build(t, make([]float32, MinHop*3000+100))
+	// Halved until there are no more than 1024 columns, the leftover samples don't make a column
+	want := []struct{ hop, cols int }{{512, 3000}, {1024, 1500}, {2048, 750}}
+	if len(s.Levels) != len(want) {
+		t.Fatalf("got %d levels, want %d", len(s.Levels), len(want))
+	}
+	for idx, it := range want {
+		lvl := s.Levels[idx]
+		if lvl.Hop != it.hop || len(lvl.Columns) != it.cols {
+			t.Errorf("level %d: got hop %d with %d columns, want hop %d with %d", idx, lvl.Hop, len(lvl.Columns), it.hop, it.cols)
+		}
+	}
+	if got := s.GetLevel(1500).Hop; got != 1024 {
+		t.Errorf("level for 1500 samples per px: got hop %d, want 1024", got)
+	}
+}
+
+func TestToneBin(t *testing.T) {
+	const bin = 64
+	freq := float64(bin) * testRate / FFTSize
+	samples := make([]float32, MinHop*16)
+	for i := range samples {
+		samples[i] = float32(math.Sin(2 * math.Pi * freq * float64(i) / testRate))
+	}
+	s := build(t, samples)
+	if got := s.BinFromFreq(freq); got != bin {
+		t.Fatalf("BinFromFreq(%.0f): got %d, want %d", freq, got, bin)
+	}
+	col := s.Levels[0].Columns[8]
+	// Full scale sine is 0 dB, the top of the range
+	if col[bin] < 250 {
+		t.Errorf("tone bin: got %d, want about 255", col[bin])
+	}
+	for _, far := range []int{bin / 4, bin * 4} {
+		if col[far] > col[bin]/2 {
+			t.Errorf("bin %d: got %d, far from the tone", far, col[far])
+		}
+	}
+}
+
+// Every column of a coarser level keeps the louder of the two columns it's made of
+func TestMaxPooling(t *testing.T) {
+	samples := make([]float32, MinHop*2100)
+	// A short click every 700 columns, so pairs have one loud and one quiet column
+	for col := 0; col < 2100; col += 701 {
+		samples[col*MinHop+MinHop/2] = 1
+	}
+	s := build(t, samples)
+	for idx := 1; idx < len(s.Levels); idx++ {
+		prev, lvl := s.Levels[idx-1], s.Levels[idx]
+		for i, col := range lvl.Columns {
+			for bin := range Bins {
+				if want := max(prev.Columns[i*2][bin], prev.Columns[i*2+1][bin]); col[bin] != want {
+					t.Fatalf("level %d, column %d, bin %d: got %d, want %d", idx, i, bin, col[bin], want)
+				}
+			}
+		}
+	}
+	if got := s.Levels[1].Columns[350][Bins/2]; got == 0 {
+		t.Error("click is lost on the coarser level")
+	}
+}
@@ -0,0 +1,11 @@
+package spectrogram
+
+import "math"
+
+func hannWindow(size int) []float64 {
+	w := make([]float64, size)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size-1))
+	}
+	return w
+}
@@ -0,0 +1,85 @@
+package state
+
+import (
+	"context"
+	"errors"
+
+	"gioui.org/x/explorer"
+	"github.com/spyhere/re-peat/internal/align"
+	"github.com/spyhere/re-peat/internal/audio"
+)
+
+// Search for the offset between the previous version of the loaded audio and the current one
+type Alignment struct {
+	IsRunning bool
+	File      string // previous version of the audio
+	Result    align.Result
+	Err       error
+	Seq       int // increased every time alignment finishes
+}
+
+// Asks for the previous version of the loaded audio and finds how far its content moved.
+// "maxOffset" limits the search in samples
+func (a *AppState) AlignWithPrevious(maxOffset int) {
+	a.isChoosing = true
+	a.fileManager.Load(func(filePath string, err error) {
+		a.isChoosing = false
+		if err != nil {
+			if !errors.Is(err, explorer.ErrUserDecline) {
+				a.Lg.Error("AlignWithPrevious", err)
+			}
+			return
+		}
+		a.ResetAlignment()
+		ctx, cancel := context.WithCancel(context.Background())
+		a.cancelAlignment = cancel
+		file := a.LoadedAFile
+		seq := a.Alignment.Seq
+		a.Alignment.IsRunning, a.Alignment.File = true, filePath
+		a.window.Invalidate()
+
+		result, err := a.alignFiles(ctx, filePath, maxOffset)
+		if errors.Is(err, context.Canceled) || ctx.Err() != nil || file != a.LoadedAFile {
+			return
+		}
+		a.cancelAlignment = nil
+		a.Alignment = Alignment{File: filePath, Result: result, Err: err, Seq: seq + 1}
+		if err != nil && !errors.Is(err, align.ErrTooShort) {
+			go a.reportDecodeError("AlignWithPrevious", filePath, err)
+		}
+		a.Lg.Info("Aligned with previous audio", "file", filePath, "offset", result.Offset, "confidence", result.Confidence)
+		a.window.Invalidate()
+	}, audio.Extensions...)
+}
+
+func (a *AppState) alignFiles(ctx context.Context, prevFile string, maxOffset int) (align.Result, error) {
+	sampleRate := a.AudioMeta.SampleRate
+	frames := align.Frames(sampleRate, maxOffset)
+	prev, err := audio.DecodeMono(ctx, prevFile, sampleRate, frames)
+	if err != nil {
+		return align.Result{}, err
+	}
+	var cur []float32
+	if samples := a.Samples; !samples.IsEmpty() {
+		cur = samples.Mid[:min(frames, samples.Len())]
+	} else if cur, err = audio.DecodeMono(ctx, a.LoadedAFile, sampleRate, frames); err != nil {
+		return align.Result{}, err
+	}
+	return align.Offset(ctx, prev, cur, sampleRate, maxOffset)
+}
+
+func (a *AppState) ResetAlignment() {
+	if a.cancelAlignment != nil {
+		a.cancelAlignment()
+		a.cancelAlignment = nil
+	}
+	a.Alignment = Alignment{Seq: a.Alignment.Seq}
+}
+
+// Moves all markers by "offset" samples, returns how many of them were redacted
+func (a *AppState) ShiftMarkers(offset int) int {
+	redacted := a.TimeMarkers.Shift(offset, a.AudioMeta.MaxMonoSamples())
+	a.ChipsFilter.Recreate(a.TimeMarkers)
+	a.Lg.Info("Markers shifted", "offset", offset, "redacted", redacted)
+	return redacted
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"slices"
+
+	"github.com/spyhere/re-peat/internal/loudness"
+	p "github.com/spyhere/re-peat/internal/player"
+)
+
+func (a *AppState) setTrackGain(db float64) {
+	a.TrackGain = db
+	a.hasTrackGain = true
+}
+
+func (a *AppState) resetTrackGain() {
+	a.TrackGain = 0
+	a.hasTrackGain = false
+}
+
+func (a *AppState) HasTrackGain() bool {
+	return a.hasTrackGain
+}
+
+func (a *AppState) TrackGainString() string {
+	if !a.hasTrackGain {
+		return ""
+	}
+	return loudness.FormatGain(a.TrackGain)
+}
+
+// Track normalisation together with per-marker volume ramps
+func (a *AppState) VolumeEnvelope() p.Envelope {
+	env := p.Envelope{Base: a.TrackGain}
+	for _, it := range a.TimeMarkers {
+		if it.Gain == nil || !it.IsAlive() {
+			continue
+		}
+		env.Points = append(env.Points, p.AutomationPoint{
+			Samples: it.Samples,
+			Gain:    *it.Gain,
+			Ramp:    int(it.Ramp * float64(a.AudioMeta.SampleRate)),
+		})
+	}
+	slices.SortFunc(env.Points, func(a, b p.AutomationPoint) int {
+		return a.Samples - b.Samples
+	})
+	return env
+}
+
+// Pushes the envelope to the player if markers or track gain were changed
+func (a *AppState) SyncVolumeEnvelope() {
+	if a.Player == nil {
+		return
+	}
+	env := a.VolumeEnvelope()
+	if env.Equal(a.envelope) {
+		return
+	}
+	a.envelope = env
+	a.Player.SetEnvelope(env)
+}
@@ -0,0 +1,141 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gioui.org/x/explorer"
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/bundle"
+)
+
+// Where opened bundles are unpacked, the audio has to stay on disk while it plays
+func bundlesDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "re-peat", "bundles"), nil
+}
+
+// Saves the loaded audio, the markers and the player volume into a single file
+func (a *AppState) BundleSave() {
+	if !a.HasAudioLoaded() {
+		a.Lg.Warn("BundleSave: unreachable. Audio is not loaded")
+		return
+	}
+	data, err := a.encodeMarkers()
+	if err != nil {
+		a.Lg.Error("BundleSave", err)
+		return
+	}
+	settings := bundle.Settings{Volume: defaultPlayerVol, Tempo: a.Tempo}
+	if a.Player != nil {
+		settings.Volume, settings.Muted = a.Player.GetVolume()
+	}
+	audioPath := a.LoadedAFile
+	name := strings.TrimSuffix(a.AFileMeta.Name, filepath.Ext(a.AFileMeta.Name)) + bundle.Ext
+	a.isChoosing = true
+	a.fileManager.SaveAsStream(name, func(w io.Writer) error {
+		return bundle.Write(w, []string{audioPath}, data, settings)
+	}, func(filePath string, err error) {
+		a.isChoosing = false
+		if err != nil {
+			if !errors.Is(err, explorer.ErrUserDecline) {
+				a.Lg.Error("BundleSave", err)
+			}
+			return
+		}
+		a.Lg.Info("Bundle saved")
+	})
+}
+
+// Opens audio and markers of the bundle, if they match the hashes they were saved with. This blocks goroutine
+func (a *AppState) openBundle(filePath string) {
+	base, err := bundlesDir()
+	if err == nil {
+		err = os.MkdirAll(base, 0o755)
+	}
+	if err != nil {
+		a.Lg.Error("openBundle", err)
+		return
+	}
+	dir, err := os.MkdirTemp(base, "bundle-")
+	if err != nil {
+		a.Lg.Error("openBundle", err)
+		return
+	}
+	name := filepath.Base(filePath)
+	extracted, err := bundle.Extract(filePath, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		a.Lg.Warn("Bundle can't be opened", "file", name, "err", err)
+		p := a.I18n.Project
+		a.Prompter.Tell(p.BundleBrokenTitle, fmt.Sprintf(p.BundleBrokenBody, name, err))
+		return
+	}
+	saveStruct, err := decodeMarkersFile(extracted.Markers)
+	if err != nil {
+		os.RemoveAll(dir)
+		a.Lg.Error("openBundle", err)
+		return
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		os.RemoveAll(dir)
+		a.Lg.Error("openBundle", err)
+		return
+	}
+	if !a.loadAudio(extracted.Audio[0]) {
+		os.RemoveAll(dir)
+		return
+	}
+	removeStaleBundles(base, dir)
+	// Markers can be saved elsewhere, but not back into the bundle
+	a.setLoadedMarkers(saveStruct, fileInfo)
+	settings := extracted.Manifest.Settings
+	if settings.Volume > 0 {
+		a.Player.SetVolume(settings.Volume)
+	}
+	if settings.Muted {
+		a.Player.SetVolume(0)
+	}
+	// Markers saved without a tempo still get the one of the bundle
+	if a.Tempo == 0 && settings.Tempo > 0 {
+		a.SetTempo(settings.Tempo)
+	}
+	a.Lg.Info("Bundle opened", "file", name, "audio", len(extracted.Audio))
+}
+
+// Previously opened bundles are not needed anymore. The ones still in use on Windows are removed next time
+func removeStaleBundles(base, keep string) {
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return
+	}
+	for _, it := range entries {
+		if dir := filepath.Join(base, it.Name()); dir != keep {
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+// Opens a file the app was started with: a bundle, or audio. This blocks goroutine
+func (a *AppState) OpenOnStartup(filePath string) {
+	switch ext := strings.ToLower(filepath.Ext(filePath)); {
+	case ext == bundle.Ext:
+		a.openBundle(filePath)
+	case slices.Contains(audio.Extensions, ext):
+		if a.loadAudio(filePath) {
+			a.offerEmbeddedMarkers(filePath)
+		}
+	default:
+		a.Lg.Warn("OpenOnStartup: unknown file", "file", filePath)
+	}
+	a.window.Invalidate()
+}
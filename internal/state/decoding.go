@@ -0,0 +1,145 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/spyhere/re-peat/internal/audio"
+)
+
+// Samples decoded so far, shared between the decoding goroutine and the UI.
+// The result is handed over when done, SyncDecodedSamples picks it up
+type decoding struct {
+	mu       sync.Mutex
+	file     string
+	samples  audio.Samples
+	progress float64
+	done     bool
+	err      error
+}
+
+func (d *decoding) set(samples audio.Samples, progress float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.samples = samples
+	d.progress = progress
+}
+
+func (d *decoding) get() (audio.Samples, float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.samples, d.progress
+}
+
+func (d *decoding) finish(samples audio.Samples, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.samples = samples
+	d.err = err
+	d.done = true
+}
+
+func (d *decoding) result() (audio.Samples, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.samples, d.done, d.err
+}
+
+func (a *AppState) DecodeAllSamples() {
+	if a.isDecoding {
+		return
+	}
+	a.isDecoding = true
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancelDecoding = cancel
+	progress := &decoding{file: a.LoadedAFile}
+	a.decoding = progress
+	go func() {
+		samples, err := audio.DecodeSamples(ctx, progress.file, func(decoded audio.Samples, p float64) {
+			progress.set(decoded, p)
+			a.window.Invalidate()
+		})
+		if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return
+		}
+		progress.finish(samples, err)
+		a.window.Invalidate()
+	}()
+}
+
+// Takes the decoded samples if decoding of the loaded audio is done.
+// Decoding that was reset or replaced by another one is dropped here
+func (a *AppState) SyncDecodedSamples() {
+	progress := a.decoding
+	if progress == nil {
+		return
+	}
+	samples, done, err := progress.result()
+	if !done || progress.file != a.LoadedAFile {
+		return
+	}
+	a.isDecoding = false
+	a.cancelDecoding = nil
+	a.decoding = nil
+	if err != nil {
+		go a.reportDecodeError("Decoding samples", progress.file, err)
+		return
+	}
+	a.Lg.Info("Decoded samples", "file", progress.file)
+	a.Samples = samples
+	if !samples.IsEmpty() {
+		a.analyzeLoudness(progress.file, samples)
+	}
+}
+
+func (a *AppState) resetDecoding() {
+	if a.cancelDecoding != nil {
+		a.cancelDecoding()
+		a.cancelDecoding = nil
+	}
+	a.isDecoding = false
+	a.decoding = nil
+	a.Samples = a.Samples.Reset()
+}
+
+// Samples decoded so far and progress in 0..1 while decoding is in progress
+func (a *AppState) DecodingProgress() (audio.Samples, float64) {
+	if a.decoding == nil {
+		return audio.Samples{}, 0
+	}
+	return a.decoding.get()
+}
+
+// Decoding errors caused by the file content are explained to the user, the rest are app errors.
+// This blocks goroutine
+func (a *AppState) reportDecodeError(ctx string, file string, err error) {
+	var decodeErr *audio.DecodeError
+	if !errors.As(err, &decodeErr) {
+		a.Lg.Error(ctx, err)
+		return
+	}
+	a.Lg.Warn(ctx, "file", file, "err", err)
+	c := a.I18n.Common
+	name := filepath.Base(file)
+	var body string
+	switch decodeErr.Kind {
+	case audio.ErrKindUnsupportedCodec:
+		if decodeErr.Format == audio.FormatUnknown {
+			body = fmt.Sprintf(c.DecodeUnknown, name, audio.SupportedFormats())
+		} else {
+			body = fmt.Sprintf(c.DecodeUnsupported, name, decodeErr.Format, audio.SupportedFormats())
+		}
+	case audio.ErrKindTruncated:
+		body = fmt.Sprintf(c.DecodeTruncated, name)
+	case audio.ErrKindChannels:
+		body = fmt.Sprintf(c.DecodeChannels, name, decodeErr.Channels, audio.MaxChannels)
+	case audio.ErrKindCorrupted:
+		body = fmt.Sprintf(c.DecodeCorrupted, name, decodeErr.Format)
+	default:
+		body = fmt.Sprintf(c.DecodeBadHeader, name, decodeErr.Format)
+	}
+	a.Prompter.Tell(c.DecodeErrorTitle, body)
+}
@@ -0,0 +1,52 @@
+package state
+
+import (
+	"path/filepath"
+
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+// What changed in the current markers since the loaded .rpt was saved.
+// "Old" markers of changes are the saved ones, "New" ones are the current
+type MarkersDiff struct {
+	File    string
+	Changes []tm.Change
+	saved   tm.TimeMarkers
+}
+
+// Reads the loaded .rpt again to compare the current markers with it
+func (a *AppState) MarkersDiffLoad() (*MarkersDiff, bool) {
+	if !a.HasMarkersLoaded() {
+		a.Lg.Warn("MarkersDiffLoad: unreachable, no markers file")
+		return nil, false
+	}
+	saveStruct, err := decodeMarkersFile(a.LoadedMFile)
+	if err != nil {
+		a.Lg.Error("MarkersDiffLoad", err)
+		return nil, false
+	}
+	// The user agreed to this when the file was loaded
+	if a.AFileMeta.Name != saveStruct.FName {
+		saveStruct.Markers.SanitizeSamples(a.AudioMeta.MaxMonoSamples())
+	}
+	diff := &MarkersDiff{File: filepath.Base(a.LoadedMFile), saved: saveStruct.Markers}
+	diff.Changes = tm.Diff(diff.saved, a.TimeMarkers, a.matchTolerance())
+	return diff, true
+}
+
+// Brings one changed marker back to how it's saved, the rest of the changes are compared again
+func (a *AppState) MarkersDiffRevert(diff *MarkersDiff, idx int) {
+	if idx < 0 || idx >= len(diff.Changes) {
+		a.Lg.Warn("MarkersDiffRevert: unreachable", "idx", idx, "changes", len(diff.Changes))
+		return
+	}
+	if !a.TimeMarkers.RevertChange(diff.Changes[idx]) {
+		a.Lg.Warn("MarkersDiffRevert: markers limit is reached", "limit", tm.Limit)
+		return
+	}
+	a.TimeMarkers.DeleteDead()
+	a.MarkersMeta = tm.NewMarkersMeta(a.TimeMarkers)
+	a.ChipsFilter.Recreate(a.TimeMarkers)
+	diff.Changes = tm.Diff(diff.saved, a.TimeMarkers, a.matchTolerance())
+	a.Lg.Info("Marker change reverted", "file", diff.File)
+}
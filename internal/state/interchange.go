@@ -0,0 +1,358 @@
+package state
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gioui.org/x/explorer"
+	"github.com/spyhere/re-peat/internal/filemanager"
+	"github.com/spyhere/re-peat/internal/markerio"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+// How many row errors are listed in the import report
+const maxReportedRows = 10
+
+// Choices of the export dialog
+type ExportOptions struct {
+	Filtered   bool // only markers shown by the search and tags filter
+	Thumbnails bool
+	Notes      bool
+	Tempo      float64 // becomes the project tempo, 0 keeps the current one
+}
+
+func (a *AppState) markersDocument(opts ExportOptions) markerio.Document {
+	all := a.TimeMarkers.Sorted()
+	doc := markerio.Document{
+		Markers:   all,
+		Target:    a.markersTarget(),
+		WithNotes: opts.Notes,
+	}
+	if opts.Filtered && a.IsMarkersFiltered() {
+		doc.Markers = tm.NewTimeMarkers()
+		for idx, it := range all {
+			if a.isMarkerShown(it) {
+				doc.Markers = append(doc.Markers, it)
+				doc.Sheet.Numbers = append(doc.Sheet.Numbers, idx+1)
+			}
+		}
+		doc.Sheet.Filter = a.describeFilter()
+	}
+	g := a.I18n.Generic
+	doc.Sheet.Title = a.AFileMeta.Name
+	doc.Sheet.Info = [][2]string{
+		{g.Length, a.AudioMeta.SecondsString()},
+		{g.Size, a.AFileMeta.SizeString()},
+		{g.AudioChannels, a.AudioMeta.ChannelsString(a.I18n)},
+		{g.SampleRate, a.AudioMeta.SampleRateString()},
+		{g.Modified, a.AFileMeta.UpdatedAtString()},
+		{g.Markers, strconv.Itoa(len(doc.Markers))},
+	}
+	doc.Sheet.Labels = markerio.SheetLabels{Time: g.Time, Name: g.Name, Tags: g.Tags, Notes: g.Notes, Page: a.I18n.Project.ExportPage}
+	if opts.Thumbnails {
+		if a.Samples.IsEmpty() {
+			a.Lg.Warn("Thumbnails are skipped, audio is not decoded yet")
+		} else {
+			doc.Sheet.Thumbnails = markerio.Thumbnails(a.Samples.Mid, doc.Markers, a.AudioMeta.SampleRate)
+		}
+	}
+	return doc
+}
+
+// Search or tags filter hide some of the markers
+func (a *AppState) IsMarkersFiltered() bool {
+	return a.SearchbarV != "" || len(a.ChipsFilter.GetEnabledChips()) > 0
+}
+
+func (a *AppState) isMarkerShown(m *tm.TimeMarker) bool {
+	return a.ChipsFilter.HasMarkerEnabled(m) && strings.Contains(strings.ToLower(m.Name), strings.ToLower(a.SearchbarV))
+}
+
+func (a *AppState) ShownMarkersCount() int {
+	count := 0
+	for _, it := range a.TimeMarkers {
+		if it.IsAlive() && a.isMarkerShown(it) {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *AppState) describeFilter() string {
+	p := a.I18n.Project
+	var parts []string
+	if a.SearchbarV != "" {
+		parts = append(parts, fmt.Sprintf(p.ExportFilterSearch, a.SearchbarV))
+	}
+	if chips := a.ChipsFilter.GetEnabledChips(); len(chips) > 0 {
+		parts = append(parts, fmt.Sprintf(p.ExportFilterTags, strings.Join(chips, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (a *AppState) markersTarget() markerio.Target {
+	return markerio.Target{
+		Audio:     a.AudioMeta,
+		AudioName: a.AFileMeta.Name,
+		AudioPath: a.LoadedAFile,
+		Tempo:     a.Tempo,
+	}
+}
+
+// Tempo outside of the range DAWs accept is ignored
+func (a *AppState) SetTempo(bpm float64) {
+	if bpm < markerio.MinTempo || bpm > markerio.MaxTempo {
+		a.Lg.Warn("SetTempo: out of range", "bpm", bpm)
+		return
+	}
+	a.Tempo = bpm
+}
+
+func (a *AppState) TempoString() string {
+	if a.Tempo == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(a.Tempo, 'f', -1, 64) + " BPM"
+}
+
+// Name of the exported file, based on the audio file name. Audio with markers doesn't suggest overwriting the original
+func (a *AppState) exportName(ext string) string {
+	name := strings.TrimSuffix(a.AFileMeta.Name, filepath.Ext(a.AFileMeta.Name))
+	if name == "" {
+		name = "markers"
+	}
+	if strings.EqualFold(name+ext, a.AFileMeta.Name) {
+		name += " (markers)"
+	}
+	return name + ext
+}
+
+// Export formats available for the loaded audio
+func (a *AppState) MarkersExporters() []markerio.Exporter {
+	return markerio.ExportersFor(a.markersTarget())
+}
+
+func (a *AppState) MarkersExport(exporter markerio.Exporter, opts ExportOptions) {
+	if a.TimeMarkers.IsEmpty() {
+		a.Lg.Warn("MarkersExport: unreachable. Markers are empty")
+		return
+	}
+	if opts.Tempo > 0 {
+		a.SetTempo(opts.Tempo)
+	}
+	doc := a.markersDocument(opts)
+	if doc.Markers.IsEmpty() {
+		a.Lg.Warn("MarkersExport: unreachable. Filter hides all markers")
+		return
+	}
+	if exporter.Check == nil {
+		a.exportDocument(exporter, doc)
+		return
+	}
+	issues := exporter.Check(doc)
+	if len(issues) == 0 {
+		a.exportDocument(exporter, doc)
+		return
+	}
+	a.isChoosing = true
+	go func() {
+		p := a.I18n.Project
+		body := a.describeExportIssues(issues)
+		if !a.Prompter.Choose(fmt.Sprintf(p.ExportIssuesTitle, exporter.Name), body, a.I18n.Generic.Cancel, p.Export) {
+			a.isChoosing = false
+			return
+		}
+		a.exportDocument(exporter, doc)
+	}()
+}
+
+// Audio formats copy the whole track, so the document is written right into the chosen file
+func (a *AppState) exportDocument(exporter markerio.Exporter, doc markerio.Document) {
+	a.isChoosing = true
+	a.fileManager.SaveAsStream(a.exportName(exporter.Ext), func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		if err := exporter.Encode(bw, doc); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}, func(filePath string, err error) {
+		a.isChoosing = false
+		if err != nil {
+			if !errors.Is(err, explorer.ErrUserDecline) {
+				a.Lg.Error("MarkersExport", err)
+			}
+			return
+		}
+		a.Lg.Info("Markers exported", "format", exporter.Name)
+	})
+}
+
+func (a *AppState) MarkersImport() {
+	a.pausePlayer()
+	a.isChoosing = true
+	a.fileManager.Load(func(filePath string, err error) {
+		a.isChoosing = false
+		if err != nil {
+			if !errors.Is(err, explorer.ErrUserDecline) {
+				a.Lg.Error("MarkersImport", err)
+			}
+			return
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			a.Lg.Error("MarkersImport", err)
+			return
+		}
+		defer file.Close()
+		br := bufio.NewReader(file)
+		head, _ := br.Peek(512)
+		importer, ok := markerio.ImporterFor(filePath, head)
+		if !ok {
+			a.Lg.Warn("MarkersImport: unknown format", "file", filePath)
+			return
+		}
+		var markers tm.TimeMarkers
+		var rowErrs []markerio.RowError
+		if importer.ReadTable != nil {
+			markers, rowErrs, err = a.decodeTable(br, importer)
+		} else {
+			markers, rowErrs, err = importer.Decode(br, a.markersTarget())
+		}
+		if errors.Is(err, errImportCanceled) {
+			return
+		}
+		if errors.Is(err, markerio.ErrNoTimeColumn) {
+			a.Prompter.Tell(a.I18n.Project.ImportReportTitle, a.I18n.Project.ImportNoTime)
+			return
+		}
+		if err != nil {
+			a.Lg.Error("MarkersImport", err)
+			return
+		}
+		a.applyImportedMarkers(filepath.Base(filePath), markers, rowErrs)
+	}, markerio.ImportExtensions()...)
+}
+
+// Offers markers written into the loaded WAV or FLAC. This blocks goroutine
+func (a *AppState) offerEmbeddedMarkers(filePath string) {
+	markers, rowErrs, err := markerio.ReadEmbedded(filePath, a.markersTarget())
+	if err != nil {
+		a.Lg.Warn("Embedded markers can't be read", "file", filePath, "err", err)
+		return
+	}
+	if markers.IsEmpty() {
+		return
+	}
+	p := a.I18n.Project
+	name := filepath.Base(filePath)
+	if !a.Prompter.Choose(p.ImportEmbeddedTitle, fmt.Sprintf(p.ImportEmbeddedBody, len(markers), name), a.I18n.Generic.Cancel, p.Import) {
+		return
+	}
+	a.applyImportedMarkers(name, markers, rowErrs)
+}
+
+// One line for every kind of issue, listing marker numbers
+func (a *AppState) describeExportIssues(issues []markerio.Issue) string {
+	p := a.I18n.Project
+	var rounded int
+	var maxShift float64
+	var replaced, truncated, dropped, nonASCII []string
+	for _, it := range issues {
+		number := fmt.Sprintf("%02d", it.Marker+1)
+		switch it.Kind {
+		case markerio.IssueRounded:
+			rounded++
+			maxShift = max(maxShift, math.Abs(it.Shift))
+		case markerio.IssueReplaced:
+			replaced = append(replaced, number)
+		case markerio.IssueTruncated:
+			truncated = append(truncated, number)
+		case markerio.IssueDropped:
+			dropped = append(dropped, number)
+		case markerio.IssueNonASCII:
+			nonASCII = append(nonASCII, number)
+		}
+	}
+	lines := make([]string, 0, 5)
+	if rounded > 0 {
+		lines = append(lines, fmt.Sprintf(p.ExportRounded, rounded, int(math.Ceil(maxShift*1000))))
+	}
+	if len(replaced) > 0 {
+		lines = append(lines, fmt.Sprintf(p.ExportReplaced, strings.Join(replaced, ", ")))
+	}
+	if len(truncated) > 0 {
+		lines = append(lines, fmt.Sprintf(p.ExportTruncated, strings.Join(truncated, ", ")))
+	}
+	if len(nonASCII) > 0 {
+		lines = append(lines, fmt.Sprintf(p.ExportNonASCII, strings.Join(nonASCII, ", ")))
+	}
+	if len(dropped) > 0 {
+		lines = append(lines, fmt.Sprintf(p.ExportDropped, strings.Join(dropped, ", ")))
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+var errImportCanceled = errors.New("import is canceled")
+
+// Asks the user which columns hold marker fields. This blocks goroutine
+func (a *AppState) decodeTable(r io.Reader, importer markerio.Importer) (tm.TimeMarkers, []markerio.RowError, error) {
+	table, err := importer.ReadTable(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	g := a.I18n.Generic
+	fields := []string{g.Time, a.I18n.Project.ImportSamples, g.Name, g.Tags, g.Notes}
+	guessed := table.GuessMapping()
+	chosen, ok := a.Prompter.MapColumns(a.I18n.Project.ImportColumnsTitle, fields, table.Columns(), guessed[:])
+	if !ok {
+		return nil, nil, errImportCanceled
+	}
+	var mapping markerio.Mapping
+	copy(mapping[:], chosen)
+	return table.Decode(mapping, a.AudioMeta)
+}
+
+// Merges with or replaces current markers, asking the user if there are any. This blocks goroutine
+func (a *AppState) applyImportedMarkers(name string, markers tm.TimeMarkers, rowErrs []markerio.RowError) {
+	p := a.I18n.Project
+	imported, overLimit := len(markers), 0
+	if !markers.IsEmpty() {
+		merge := false
+		if !a.TimeMarkers.IsEmpty() {
+			body := fmt.Sprintf(p.ImportMergeBody, len(markers), name, len(a.TimeMarkers))
+			merge = a.Prompter.Choose(p.ImportMergeTitle, body, p.ImportReplace, p.ImportMerge)
+		}
+		if merge {
+			imported, overLimit = a.TimeMarkers.Merge(markers)
+		} else {
+			a.TimeMarkers = markers
+			a.LoadedMFile = ""
+			a.MFileMeta = filemanager.FileMeta{}
+		}
+		a.MarkersMeta = tm.NewMarkersMeta(a.TimeMarkers)
+		a.ChipsFilter.Recreate(a.TimeMarkers)
+		a.Lg.Info("Markers imported", "file", name, "merge", merge, "skipped", len(rowErrs))
+	}
+	if len(rowErrs) == 0 && overLimit == 0 && !markers.IsEmpty() {
+		return
+	}
+	body := fmt.Sprintf(p.ImportReportBody, imported, name)
+	if overLimit > 0 {
+		body += "\n" + fmt.Sprintf(p.ImportOverLimit, overLimit, tm.Limit)
+	}
+	if len(rowErrs) > 0 {
+		rows := make([]string, 0, maxReportedRows)
+		for _, it := range rowErrs[:min(len(rowErrs), maxReportedRows)] {
+			rows = append(rows, it.Error())
+		}
+		body += "\n\n" + fmt.Sprintf(p.ImportSkipped, len(rowErrs), strings.Join(rows, "\n"))
+	}
+	a.Prompter.Tell(p.ImportReportTitle, body)
+}
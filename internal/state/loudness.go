@@ -0,0 +1,77 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/loudness"
+)
+
+func (a *AppState) analyzeLoudness(file string, samples audio.Samples) {
+	if a.cancelLoudness != nil {
+		a.cancelLoudness()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancelLoudness = cancel
+	sampleRate, channels := a.AudioMeta.SampleRate, a.AudioMeta.Channels
+	go func() {
+		analysis, err := loudness.Analyze(ctx, samples, sampleRate, channels)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				a.Lg.Error("Analyzing loudness", err)
+			}
+			return
+		}
+		if file != a.LoadedAFile {
+			return
+		}
+		a.Loudness = analysis
+		if !a.hasTrackGain {
+			a.setTrackGain(loudness.NormalisationGain(analysis.Track))
+		}
+		a.Lg.Info("Analyzed loudness", "file", file, "lufs", analysis.Track.LUFSString())
+		a.window.Invalidate()
+	}()
+}
+
+func (a *AppState) resetLoudness() {
+	if a.cancelLoudness != nil {
+		a.cancelLoudness()
+		a.cancelLoudness = nil
+	}
+	a.Loudness = loudness.Analysis{}
+}
+
+// Loudness of the section that starts at the marker with "idx" and lasts until the next one
+func (a *AppState) SectionLoudness(idx int) loudness.Stats {
+	cur := a.TimeMarkers.Get(idx, true)
+	if cur == nil {
+		return loudness.Stats{}
+	}
+	to := a.AudioMeta.MonoSamplesLen
+	if next := a.TimeMarkers.Get(idx+1, true); next != nil {
+		to = next.Samples
+	}
+	return a.Loudness.Measure(cur.Samples, to)
+}
+
+// Indexes of markers starting the quietest and the loudest sections, -1 if nothing was measured
+func (a *AppState) LoudnessExtremes() (quietest int, loudest int) {
+	quietest, loudest = -1, -1
+	var low, high loudness.Stats
+	for idx := range a.TimeMarkers {
+		stats := a.SectionLoudness(idx)
+		if !stats.IsMeasured() || math.IsInf(stats.LUFS, -1) {
+			continue
+		}
+		if quietest == -1 || stats.LUFS < low.LUFS {
+			quietest, low = idx, stats
+		}
+		if loudest == -1 || stats.LUFS > high.LUFS {
+			loudest, high = idx, stats
+		}
+	}
+	return quietest, loudest
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"gioui.org/x/explorer"
+	tm "github.com/spyhere/re-peat/internal/timeMarkers"
+)
+
+// Markers closer than that are taken as the same marker, if names don't tell otherwise
+const matchToleranceSeconds = 0.5
+
+// Markers of another .rpt, waiting for the user to pick which side of every change to keep.
+// "Old" markers of changes are the current ones, "New" ones come from the file
+type MarkersMerge struct {
+	File    string
+	Changes []tm.Change
+}
+
+func (a *AppState) matchTolerance() int {
+	return a.AudioMeta.GetSamplesFromSeconds(matchToleranceSeconds)
+}
+
+// Merge waiting for the resolution, nil if there is none
+func (a *AppState) PendingMerge() *MarkersMerge {
+	return a.pendingMerge
+}
+
+func (a *AppState) MarkersMergeLoad() {
+	a.pausePlayer()
+	a.isChoosing = true
+	a.fileManager.Load(func(filePath string, err error) {
+		a.isChoosing = false
+		if err != nil {
+			if !errors.Is(err, explorer.ErrUserDecline) {
+				a.Lg.Error("MarkersMergeLoad", err)
+			}
+			return
+		}
+		saveStruct, ok := a.readMarkersFile("MarkersMergeLoad", filePath)
+		if !ok {
+			return
+		}
+		name := filepath.Base(filePath)
+		changes := tm.Diff(a.TimeMarkers, saveStruct.Markers, a.matchTolerance())
+		if len(changes) == 0 {
+			a.Prompter.Tell(a.I18n.Project.MergeTitle, a.I18n.Project.MergeNothing)
+			return
+		}
+		a.pendingMerge = &MarkersMerge{File: name, Changes: changes}
+		a.window.Invalidate()
+	}, ".rpt")
+}
+
+// Applies changes the user took from the other file and drops the pending merge
+func (a *AppState) MarkersMergeResolve(take []bool) {
+	merge := a.pendingMerge
+	a.pendingMerge = nil
+	if merge == nil {
+		a.Lg.Warn("MarkersMergeResolve: unreachable, nothing to merge")
+		return
+	}
+	taken, overLimit := 0, 0
+	apply := func(removals bool) {
+		for idx, it := range merge.Changes {
+			if idx >= len(take) || !take[idx] || (it.Kind == tm.ChangeRemoved) != removals {
+				continue
+			}
+			if !a.TimeMarkers.ApplyChange(it) {
+				overLimit++
+				continue
+			}
+			taken++
+		}
+	}
+	// Removed markers make room for added ones at the limit
+	apply(true)
+	a.TimeMarkers.DeleteDead()
+	apply(false)
+	a.TimeMarkers.Sort()
+	a.MarkersMeta = tm.NewMarkersMeta(a.TimeMarkers)
+	a.ChipsFilter.Recreate(a.TimeMarkers)
+	a.Lg.Info("Markers merged", "file", merge.File, "taken", taken, "overLimit", overLimit)
+	if overLimit > 0 {
+		go a.Prompter.Tell(a.I18n.Project.MergeTitle, fmt.Sprintf(a.I18n.Project.ImportOverLimit, overLimit, tm.Limit))
+	}
+}
+
+func (a *AppState) MarkersMergeCancel() {
+	a.pendingMerge = nil
+}
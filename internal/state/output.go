@@ -0,0 +1,93 @@
+package state
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spyhere/re-peat/internal/common"
+	"github.com/spyhere/re-peat/internal/configs"
+)
+
+const outputWatchInterval = 250 * time.Millisecond
+
+// Stall noticed by the watcher, handed over from its goroutine to the UI one
+type outputStall struct {
+	mu      sync.Mutex
+	stalled bool
+}
+
+func (o *outputStall) set() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stalled = true
+}
+
+func (o *outputStall) take() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	stalled := o.stalled
+	o.stalled = false
+	return stalled
+}
+
+// Watches whether the output device stops pulling samples (e.g. it was unplugged), SyncOutputStall reacts to it.
+// The output device can't be chosen: speaker opens the system default one and can be initialised only once
+func (a *AppState) watchOutput() {
+	ticker := time.NewTicker(outputWatchInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if !a.Player.IsStalled(now) {
+			continue
+		}
+		a.outputStall.set()
+		a.window.Invalidate()
+	}
+}
+
+// Pauses the player on a stalled output, so the position is kept and playback doesn't silently run into nowhere
+func (a *AppState) SyncOutputStall() {
+	if !a.outputStall.take() || a.Player == nil || !a.Player.IsPlaying() {
+		return
+	}
+	a.Player.Pause()
+	sec := a.Player.GetCurrentSecond()
+	a.Lg.Warn("Audio output stalled", "second", sec)
+	commonI18n := a.I18n.Common
+	go a.Prompter.Tell(commonI18n.OutputStalledTitle, fmt.Sprintf(commonI18n.OutputStalledBody, common.FormatSeconds(sec)))
+}
+
+func nextOption(options []int, cur int) int {
+	idx := slices.Index(options, cur)
+	return options[(idx+1)%len(options)]
+}
+
+// Speaker is initialised once, so a new rate is used after restart if the player already exists
+func (a *AppState) CycleOutputSampleRate() {
+	a.Cfgs.OutputSampleRate = nextOption(configs.OutputSampleRates, a.Cfgs.GetOutputSampleRate())
+	a.Lg.Info("Output sample rate", "rate", a.Cfgs.OutputSampleRate)
+}
+
+// True if the configured output rate differs from the one speaker was initialised with
+func (a *AppState) IsOutputRestartNeeded() bool {
+	return a.Player != nil && a.Player.OutputSampleRate() != a.Cfgs.GetOutputSampleRate()
+}
+
+// Applied after restart if the player already exists, same as the sample rate
+func (a *AppState) CycleBufferSize() {
+	a.Cfgs.BufferMs = nextOption(configs.BufferSizesMs, a.Cfgs.GetBufferMs())
+	a.Lg.Info("Output buffer size", "ms", a.Cfgs.BufferMs)
+}
+
+func (a *AppState) IsBufferRestartNeeded() bool {
+	return a.Player != nil && a.Player.BufferSize() != time.Duration(a.Cfgs.GetBufferMs())*time.Millisecond
+}
+
+func (a *AppState) CycleResampleQuality() {
+	a.Cfgs.ResampleQuality = nextOption(configs.ResampleQualities, a.Cfgs.GetResampleQuality())
+	if a.Player != nil {
+		a.Player.SetResampleQuality(a.Cfgs.ResampleQuality)
+	}
+	a.Lg.Info("Resample quality", "quality", a.Cfgs.ResampleQuality)
+}
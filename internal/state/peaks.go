@@ -0,0 +1,108 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/peakcache"
+)
+
+func newPeakKey(file string, meta audio.AudioMeta) (peakcache.Key, error) {
+	hash, err := peakcache.HashFile(file)
+	if err != nil {
+		return peakcache.Key{}, err
+	}
+	return peakcache.Key{
+		Hash:       hash,
+		SampleRate: meta.SampleRate,
+		Channels:   meta.Channels,
+		Samples:    meta.MonoSamplesLen,
+	}, nil
+}
+
+// Result of the cache lookup, handed over from its goroutine to the UI one
+type peaksLookup struct {
+	mu    sync.Mutex
+	file  string
+	key   peakcache.Key
+	peaks *peakcache.Peaks // nil on miss
+}
+
+func (l *peaksLookup) set(file string, key peakcache.Key, peaks *peakcache.Peaks) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file, l.key, l.peaks = file, key, peaks
+}
+
+func (l *peaksLookup) take() (string, peakcache.Key, *peakcache.Peaks) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	file, key, peaks := l.file, l.key, l.peaks
+	l.file, l.key, l.peaks = "", peakcache.Key{}, nil
+	return file, key, peaks
+}
+
+// Looks up peaks of the just loaded audio in the on-disk cache, SyncStoredPeaks picks the result up
+func (a *AppState) loadStoredPeaks(file string, meta audio.AudioMeta) {
+	if a.peakStore == nil {
+		return
+	}
+	go func() {
+		key, err := newPeakKey(file, meta)
+		if err != nil {
+			a.Lg.Error("Hashing audio", err)
+			return
+		}
+		peaks, err := a.peakStore.Load(key)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			a.Lg.Error("Loading stored peaks", err)
+		}
+		a.peaksLookup.set(file, key, peaks)
+		a.window.Invalidate()
+	}()
+}
+
+// Takes the cache lookup result if it's for the loaded audio, decoding starts right away on hit
+func (a *AppState) SyncStoredPeaks() {
+	file, key, peaks := a.peaksLookup.take()
+	if file == "" || file != a.LoadedAFile {
+		return
+	}
+	a.peakKey = key
+	if peaks == nil {
+		return
+	}
+	a.Lg.Info("Loaded stored peaks", "file", file)
+	a.StoredPeaks = peaks
+	a.DecodeAllSamples()
+}
+
+// Saves peaks built for the loaded audio so the next time it opens instantly
+func (a *AppState) StorePeaks(peaks *peakcache.Peaks) {
+	a.StoredPeaks = peaks
+	if a.peakStore == nil {
+		return
+	}
+	file, meta, key := a.LoadedAFile, a.AudioMeta, a.peakKey
+	go func() {
+		var err error
+		if key.IsEmpty() {
+			if key, err = newPeakKey(file, meta); err != nil {
+				a.Lg.Error("Hashing audio", err)
+				return
+			}
+		}
+		if err = a.peakStore.Save(key, peaks); err != nil {
+			a.Lg.Error("Storing peaks", err)
+			return
+		}
+		a.Lg.Info("Stored peaks", "file", file)
+	}()
+}
+
+func (a *AppState) resetStoredPeaks() {
+	a.StoredPeaks = nil
+	a.peakKey = peakcache.Key{}
+}
@@ -2,22 +2,27 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/x/explorer"
 	"github.com/spyhere/re-peat/internal/audio"
+	"github.com/spyhere/re-peat/internal/bundle"
 	"github.com/spyhere/re-peat/internal/common"
 	"github.com/spyhere/re-peat/internal/configs"
 	"github.com/spyhere/re-peat/internal/filemanager"
 	"github.com/spyhere/re-peat/internal/filters"
 	"github.com/spyhere/re-peat/internal/i18n"
 	"github.com/spyhere/re-peat/internal/logging"
+	"github.com/spyhere/re-peat/internal/loudness"
+	"github.com/spyhere/re-peat/internal/peakcache"
 	p "github.com/spyhere/re-peat/internal/player"
 	"github.com/spyhere/re-peat/internal/playhead"
 	"github.com/spyhere/re-peat/internal/prompt"
@@ -39,6 +44,10 @@ func NewAppState(window *app.Window, lg logging.Logger, cfgs *configs.Configs) (
 		lg.Warn("Failed to get locale", "err", err)
 	}
 	newI18n := i18n.NewI18n(i18n.Parse(locale))
+	peakStore, err := peakcache.NewUserStore(int64(cfgs.GetPeakCacheMB()) << 20)
+	if err != nil {
+		lg.Warn("Waveform cache is disabled", "err", err)
+	}
 	return AppState{
 		Cfgs:        cfgs,
 		Lg:          lg,
@@ -50,6 +59,7 @@ func NewAppState(window *app.Window, lg logging.Logger, cfgs *configs.Configs) (
 		fileManager: filemanager.NewFileManager(window),
 		TimeMarkers: tm.NewTimeMarkers(),
 		window:      window,
+		peakStore:   peakStore,
 	}, nil
 }
 
@@ -67,7 +77,12 @@ type AppState struct {
 	LoadedAFile string
 	LoadedMFile string
 	Player      *p.Player
-	MonoSamples []float32 // NOTE: Should it stay in state or moved to Editor?
+	Samples     audio.Samples // NOTE: Should it stay in state or moved to Editor?
+	StoredPeaks *peakcache.Peaks
+	Loudness    loudness.Analysis
+	Alignment   Alignment
+	TrackGain   float64 // normalisation in dB
+	Tempo       float64 // project tempo in bpm, 0 if it's not set
 	AudioMeta   audio.AudioMeta
 	MarkersMeta tm.MarkersMeta
 	AFileMeta   filemanager.FileMeta
@@ -77,6 +92,18 @@ type AppState struct {
 	isLoading   bool
 	isDecoding  bool
 	window      *app.Window
+
+	cancelLoudness  context.CancelFunc
+	cancelAlignment context.CancelFunc
+	cancelDecoding  context.CancelFunc
+	decoding        *decoding
+	pendingMerge    *MarkersMerge
+	peakStore       *peakcache.Store
+	peakKey         peakcache.Key
+	peaksLookup     peaksLookup
+	outputStall     outputStall
+	hasTrackGain    bool
+	envelope        p.Envelope // the one player has
 }
 
 func (a *AppState) IsChoosing() bool {
@@ -104,6 +131,10 @@ func (a *AppState) resetAudioDependantState() {
 	a.LoadedMFile = ""
 	a.MFileMeta = filemanager.FileMeta{}
 	a.MarkersMeta = tm.MarkersMeta{}
+	a.resetTrackGain()
+	a.Tempo = 0
+	// New audio comes with a fresh player envelope
+	a.envelope = p.Envelope{}
 }
 
 func (a *AppState) pausePlayer() {
@@ -112,23 +143,6 @@ func (a *AppState) pausePlayer() {
 	}
 }
 
-func (a *AppState) DecodeAllSamples() {
-	if a.isDecoding {
-		return
-	}
-	a.isDecoding = true
-	go func() {
-		monoSamples, err := audio.FileToMonosamples(a.LoadedAFile)
-		if err != nil {
-			a.Lg.Error("Decoding samples", err)
-		}
-		a.Lg.Info("Decoded samples", "file", a.LoadedAFile)
-		a.MonoSamples = monoSamples
-		a.isDecoding = false
-		a.window.Invalidate()
-	}()
-}
-
 func (a *AppState) AudioLoad() {
 	a.pausePlayer()
 	a.isChoosing = true
@@ -144,42 +158,63 @@ func (a *AppState) AudioLoad() {
 			return
 		}
 
-		a.isLoading = true
-		defer func() {
-			a.isLoading = false
-		}()
-
-		file, err := os.Open(filePath)
-		if err != nil {
-			a.Lg.Error("AudioLoad", err)
+		if strings.ToLower(filepath.Ext(filePath)) == bundle.Ext {
+			a.openBundle(filePath)
 			return
 		}
-		fileInfo, err := os.Stat(filePath)
-		if err != nil {
-			a.Lg.Error("AudioLoad", err)
-			return
-		}
-		var audioMeta audio.AudioMeta
-		if a.Player == nil {
-			a.Player = p.NewPlayer()
-			audioMeta, err = a.Player.SetAudio(file)
-			a.Player.SetVolume(defaultPlayerVol)
-		} else {
-			audioMeta, err = a.Player.SetAudio(file)
+		if a.loadAudio(filePath) {
+			go a.offerEmbeddedMarkers(filePath)
 		}
+	}, append(audio.Extensions, bundle.Ext)...)
+}
 
-		if err != nil {
-			a.Lg.Error("AudioLoad", err)
-			return
-		}
-		// Set everything at once only if it's happy path
-		a.MonoSamples = a.MonoSamples[:0]
-		a.AudioMeta = audioMeta
-		a.AFileMeta = filemanager.NewFileMeta(filepath.Base(filePath), fileInfo.Size(), fileInfo.ModTime())
-		a.LoadedAFile = filePath
-		a.resetAudioDependantState()
-		a.Lg.Info("Audio loaded")
-	}, ".mp3", ".wav", ".flac")
+// Opens audio in the player and resets everything which belongs to the previous one
+func (a *AppState) loadAudio(filePath string) bool {
+	a.isLoading = true
+	defer func() {
+		a.isLoading = false
+	}()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		a.Lg.Error("AudioLoad", err)
+		return false
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		a.Lg.Error("AudioLoad", err)
+		return false
+	}
+	var audioMeta audio.AudioMeta
+	if a.Player == nil {
+		a.Player = p.NewPlayer(p.Options{
+			SampleRate:      a.Cfgs.GetOutputSampleRate(),
+			BufferSize:      time.Duration(a.Cfgs.GetBufferMs()) * time.Millisecond,
+			ResampleQuality: a.Cfgs.GetResampleQuality(),
+		})
+		audioMeta, err = a.Player.SetAudio(file)
+		a.Player.SetVolume(defaultPlayerVol)
+		go a.watchOutput()
+	} else {
+		audioMeta, err = a.Player.SetAudio(file)
+	}
+
+	if err != nil {
+		go a.reportDecodeError("AudioLoad", filePath, err)
+		return false
+	}
+	// Set everything at once only if it's happy path
+	a.resetDecoding()
+	a.resetStoredPeaks()
+	a.resetLoudness()
+	a.ResetAlignment()
+	a.AudioMeta = audioMeta
+	a.AFileMeta = filemanager.NewFileMeta(filepath.Base(filePath), fileInfo.Size(), fileInfo.ModTime())
+	a.LoadedAFile = filePath
+	a.resetAudioDependantState()
+	a.loadStoredPeaks(filePath, audioMeta)
+	a.Lg.Info("Audio loaded")
+	return true
 }
 
 func (a *AppState) MarkersLoad() {
@@ -198,42 +233,68 @@ func (a *AppState) MarkersLoad() {
 		}
 
 		a.LoadedMFile = ""
-		file, err := os.Open(filePath)
-		if err != nil {
-			a.Lg.Error("MarkersLoad", err)
-			return
-		}
-		var saveStruct filemanager.MarkersSaveScheme
-		decoder := json.NewDecoder(file)
-		if err = decoder.Decode(&saveStruct); err != nil {
-			a.Lg.Error("MarkersLoad", err)
+		saveStruct, ok := a.readMarkersFile("MarkersLoad", filePath)
+		if !ok {
 			return
 		}
 
-		if a.AFileMeta.Name != saveStruct.FName {
-			title := a.I18n.Project.MConflictLoadTitle
-			body := a.I18n.Project.MConflictLoadBody
-			answer := a.Prompter.Ask(title, fmt.Sprintf(body, saveStruct.FName, a.AFileMeta.Name))
-			if answer == false {
-				return
-			}
-			saveStruct.Markers.SanitizeSamples(a.AudioMeta.MaxMonoSamples())
-		}
-
 		fileInfo, err := os.Stat(filePath)
 		if err != nil {
 			a.Lg.Error("MarkersLoad", err)
 			return
 		}
-		a.TimeMarkers = saveStruct.Markers
-		a.MarkersMeta = tm.NewMarkersMeta(a.TimeMarkers)
-		a.ChipsFilter.Recreate(a.TimeMarkers)
-		a.MFileMeta = filemanager.NewFileMeta(fileInfo.Name(), fileInfo.Size(), fileInfo.ModTime())
+		a.setLoadedMarkers(saveStruct, fileInfo)
 		a.LoadedMFile = filePath
 		a.Lg.Info("Markers loaded")
 	}, ".rpt")
 }
 
+// Replaces markers, gain and tempo with the decoded ones, "fileInfo" is what is shown as their file
+func (a *AppState) setLoadedMarkers(saveStruct filemanager.MarkersSaveScheme, fileInfo os.FileInfo) {
+	a.TimeMarkers = saveStruct.Markers
+	if saveStruct.Gain != nil {
+		a.setTrackGain(*saveStruct.Gain)
+	}
+	if saveStruct.Tempo != nil {
+		a.SetTempo(*saveStruct.Tempo)
+	}
+	a.MarkersMeta = tm.NewMarkersMeta(a.TimeMarkers)
+	a.ChipsFilter.Recreate(a.TimeMarkers)
+	a.MFileMeta = filemanager.NewFileMeta(fileInfo.Name(), fileInfo.Size(), fileInfo.ModTime())
+}
+
+func decodeMarkersFile(filePath string) (filemanager.MarkersSaveScheme, error) {
+	var saveStruct filemanager.MarkersSaveScheme
+	file, err := os.Open(filePath)
+	if err != nil {
+		return saveStruct, err
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&saveStruct)
+	return saveStruct, err
+}
+
+// Decodes .rpt file, asking the user whether to go on if it was made for another audio. This blocks goroutine
+func (a *AppState) readMarkersFile(ctx, filePath string) (filemanager.MarkersSaveScheme, bool) {
+	saveStruct, err := decodeMarkersFile(filePath)
+	if err != nil {
+		a.Lg.Error(ctx, err)
+		return saveStruct, false
+	}
+
+	if a.AFileMeta.Name != saveStruct.FName {
+		title := a.I18n.Project.MConflictLoadTitle
+		body := a.I18n.Project.MConflictLoadBody
+		answer := a.Prompter.Ask(title, fmt.Sprintf(body, saveStruct.FName, a.AFileMeta.Name))
+		if answer == false {
+			return saveStruct, false
+		}
+		saveStruct.Markers.SanitizeSamples(a.AudioMeta.MaxMonoSamples())
+	}
+	return saveStruct, true
+}
+
 func (a *AppState) encodeMarkers() ([]byte, error) {
 	var data bytes.Buffer
 	encoder := json.NewEncoder(&data)
@@ -245,6 +306,12 @@ func (a *AppState) encodeMarkers() ([]byte, error) {
 		FSRate:  a.AudioMeta.SampleRate,
 		Markers: a.TimeMarkers,
 	}
+	if a.hasTrackGain {
+		saveStruct.Gain = &a.TrackGain
+	}
+	if a.Tempo > 0 {
+		saveStruct.Tempo = &a.Tempo
+	}
 	if err := encoder.Encode(saveStruct); err != nil {
 		return []byte{}, err
 	}
@@ -0,0 +1,222 @@
+package timemarkers
+
+import (
+	"cmp"
+	"slices"
+)
+
+type ChangeKind int
+
+const (
+	ChangeAdded    ChangeKind = iota // only in the new markers
+	ChangeRemoved                    // only in the old markers
+	ChangeModified                   // in both, with some fields differing
+)
+
+// Fields which differ between the old and the new marker
+type ChangeFields uint8
+
+const (
+	FieldTime ChangeFields = 1 << iota
+	FieldEnd
+	FieldName
+	FieldTags
+	FieldNotes
+	FieldAutomation // gain and ramp
+)
+
+func (f ChangeFields) Has(field ChangeFields) bool {
+	return f&field != 0
+}
+
+// Difference between two lists of markers. "Old" is nil for added markers, "New" is nil for removed ones.
+// Both point into the lists given to Diff
+type Change struct {
+	Kind   ChangeKind
+	Fields ChangeFields
+	Old    *TimeMarker
+	New    *TimeMarker
+}
+
+// How far the marker moved in samples
+func (c Change) Delta() int {
+	if c.Kind != ChangeModified {
+		return 0
+	}
+	return c.New.Samples - c.Old.Samples
+}
+
+// Position of the marker, the new one if there is one
+func (c Change) Samples() int {
+	if c.New != nil {
+		return c.New.Samples
+	}
+	return c.Old.Samples
+}
+
+func compareMarkers(a, b *TimeMarker) ChangeFields {
+	var fields ChangeFields
+	if a.Samples != b.Samples {
+		fields |= FieldTime
+	}
+	if a.End != b.End {
+		fields |= FieldEnd
+	}
+	if a.Name != b.Name {
+		fields |= FieldName
+	}
+	if !slices.Equal(a.CategoryTags, b.CategoryTags) {
+		fields |= FieldTags
+	}
+	if a.Notes != b.Notes {
+		fields |= FieldNotes
+	}
+	if !sameGain(a.Gain, b.Gain) || a.Ramp != b.Ramp {
+		fields |= FieldAutomation
+	}
+	return fields
+}
+
+func sameGain(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func distance(a, b *TimeMarker) int {
+	return max(a.Samples-b.Samples, b.Samples-a.Samples)
+}
+
+// Matches markers of both lists and lists what differs, ordered by position. Markers are paired
+// when they are at the same place with the same name, then when they have the same name within "tolerance"
+// samples, then when they are just within "tolerance", then when they have the same name anywhere.
+// The closest pairs are taken first
+func Diff(old, new TimeMarkers, tolerance int) []Change {
+	oldUsed := make([]bool, len(old))
+	newUsed := make([]bool, len(new))
+	var changes []Change
+	match := func(paired func(a, b *TimeMarker) bool) {
+		type pair struct{ o, n, dist int }
+		var pairs []pair
+		for o, a := range old {
+			if oldUsed[o] {
+				continue
+			}
+			for n, b := range new {
+				if !newUsed[n] && paired(a, b) {
+					pairs = append(pairs, pair{o, n, distance(a, b)})
+				}
+			}
+		}
+		slices.SortStableFunc(pairs, func(a, b pair) int {
+			return cmp.Compare(a.dist, b.dist)
+		})
+		for _, it := range pairs {
+			if oldUsed[it.o] || newUsed[it.n] {
+				continue
+			}
+			oldUsed[it.o], newUsed[it.n] = true, true
+			if fields := compareMarkers(old[it.o], new[it.n]); fields != 0 {
+				changes = append(changes, Change{Kind: ChangeModified, Fields: fields, Old: old[it.o], New: new[it.n]})
+			}
+		}
+	}
+	match(func(a, b *TimeMarker) bool {
+		return a.Samples == b.Samples && a.Name == b.Name
+	})
+	match(func(a, b *TimeMarker) bool {
+		return a.Name == b.Name && distance(a, b) <= tolerance
+	})
+	match(func(a, b *TimeMarker) bool {
+		return distance(a, b) <= tolerance
+	})
+	match(func(a, b *TimeMarker) bool {
+		return a.Name != "" && a.Name == b.Name
+	})
+	for idx, it := range old {
+		if !oldUsed[idx] {
+			changes = append(changes, Change{Kind: ChangeRemoved, Old: it})
+		}
+	}
+	for idx, it := range new {
+		if !newUsed[idx] {
+			changes = append(changes, Change{Kind: ChangeAdded, New: it})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return cmp.Compare(a.Samples(), b.Samples())
+	})
+	return changes
+}
+
+// Copy of the saved fields, without any UI state
+func (m *TimeMarker) clone() TimeMarker {
+	return TimeMarker{
+		Samples:      m.Samples,
+		End:          m.End,
+		Name:         m.Name,
+		Notes:        m.Notes,
+		CategoryTags: slices.Clone(m.CategoryTags),
+		Gain:         cloneGain(m.Gain),
+		Ramp:         m.Ramp,
+	}
+}
+
+func copyFields(dst, src *TimeMarker, fields ChangeFields) {
+	if fields.Has(FieldTime) {
+		dst.Samples = src.Samples
+	}
+	if fields.Has(FieldEnd) {
+		dst.End = src.End
+	}
+	if fields.Has(FieldName) {
+		dst.Name = src.Name
+	}
+	if fields.Has(FieldTags) {
+		dst.CategoryTags = slices.Clone(src.CategoryTags)
+	}
+	if fields.Has(FieldNotes) {
+		dst.Notes = src.Notes
+	}
+	if fields.Has(FieldAutomation) {
+		dst.Gain = cloneGain(src.Gain)
+		dst.Ramp = src.Ramp
+	}
+}
+
+func cloneGain(gain *float64) *float64 {
+	if gain == nil {
+		return nil
+	}
+	g := *gain
+	return &g
+}
+
+// Turns the old marker of the change into the new one. "t" must be the list old markers come from.
+// False if the limit doesn't let to add a marker. Removed markers are only marked dead
+func (t *TimeMarkers) ApplyChange(c Change) bool {
+	switch c.Kind {
+	case ChangeAdded:
+		return t.AttachNewMarker(c.New.clone())
+	case ChangeRemoved:
+		c.Old.MarkDead()
+	case ChangeModified:
+		copyFields(c.Old, c.New, c.Fields)
+	}
+	return true
+}
+
+// Turns the new marker of the change back into the old one. "t" must be the list new markers come from.
+// False if the limit doesn't let to add a marker. Added markers are only marked dead
+func (t *TimeMarkers) RevertChange(c Change) bool {
+	switch c.Kind {
+	case ChangeAdded:
+		c.New.MarkDead()
+	case ChangeRemoved:
+		return t.AttachNewMarker(c.Old.clone())
+	case ChangeModified:
+		copyFields(c.New, c.Old, c.Fields)
+	}
+	return true
+}
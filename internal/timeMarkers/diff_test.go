@@ -0,0 +1,140 @@
+package timemarkers
+
+import (
+	"testing"
+)
+
+func newList(markers ...TimeMarker) TimeMarkers {
+	list := NewTimeMarkers()
+	for _, it := range markers {
+		list.AttachNewMarker(it)
+	}
+	return list
+}
+
+func TestDiffMatchesByProximityAndName(t *testing.T) {
+	old := newList(
+		TimeMarker{Samples: 100, Name: "Intro"},
+		TimeMarker{Samples: 1000, Name: "Verse"},
+		TimeMarker{Samples: 2000, Name: "Chorus", CategoryTags: []string{"loud"}},
+		TimeMarker{Samples: 3000, Name: "Bridge"},
+		TimeMarker{Samples: 9000, Name: "Outro"},
+	)
+	new := newList(
+		TimeMarker{Samples: 100, Name: "Intro"},
+		TimeMarker{Samples: 1040, Name: "Verse"},
+		TimeMarker{Samples: 2010, Name: "Refrain", CategoryTags: []string{"loud"}},
+		TimeMarker{Samples: 5000, Name: "Solo"},
+		TimeMarker{Samples: 7000, Name: "Outro", Notes: "fade"},
+	)
+	changes := Diff(old, new, 50)
+	want := []struct {
+		kind    ChangeKind
+		fields  ChangeFields
+		samples int
+	}{
+		{ChangeModified, FieldTime, 1040},
+		{ChangeModified, FieldTime | FieldName, 2010},
+		{ChangeRemoved, 0, 3000},
+		{ChangeAdded, 0, 5000},
+		{ChangeModified, FieldTime | FieldNotes, 7000},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for idx, it := range want {
+		c := changes[idx]
+		if c.Kind != it.kind || c.Fields != it.fields || c.Samples() != it.samples {
+			t.Errorf("change %d: got kind %d fields %b at %d, want kind %d fields %b at %d",
+				idx, c.Kind, c.Fields, c.Samples(), it.kind, it.fields, it.samples)
+		}
+	}
+	if delta := changes[0].Delta(); delta != 40 {
+		t.Errorf("delta: got %d, want 40", delta)
+	}
+}
+
+func TestDiffPrefersSameNameNearby(t *testing.T) {
+	old := newList(TimeMarker{Samples: 1000, Name: "A"}, TimeMarker{Samples: 1020, Name: "B"})
+	new := newList(TimeMarker{Samples: 1010, Name: "B"}, TimeMarker{Samples: 1015, Name: "A"})
+	changes := Diff(old, new, 50)
+	for _, it := range changes {
+		if it.Kind != ChangeModified || it.Fields != FieldTime {
+			t.Errorf("got %+v, want only moved markers", it)
+		}
+	}
+	if len(changes) != 2 {
+		t.Errorf("got %d changes, want 2", len(changes))
+	}
+}
+
+func TestApplyAndRevertChanges(t *testing.T) {
+	old := newList(
+		TimeMarker{Samples: 100, Name: "Intro"},
+		TimeMarker{Samples: 1000, Name: "Verse", Notes: "quiet"},
+	)
+	new := newList(
+		TimeMarker{Samples: 1200, Name: "Verse", CategoryTags: []string{"vocals"}},
+		TimeMarker{Samples: 5000, Name: "Solo"},
+	)
+
+	applied := newList(TimeMarker{Samples: 100, Name: "Intro"}, TimeMarker{Samples: 1000, Name: "Verse", Notes: "quiet"})
+	for _, it := range Diff(applied, new, 50) {
+		applied.ApplyChange(it)
+	}
+	applied.DeleteDead()
+	if changes := Diff(applied, new, 50); len(changes) != 0 {
+		t.Errorf("after applying all changes: got %+v, want none", changes)
+	}
+
+	reverted := newList(TimeMarker{Samples: 1200, Name: "Verse", CategoryTags: []string{"vocals"}}, TimeMarker{Samples: 5000, Name: "Solo"})
+	for _, it := range Diff(old, reverted, 50) {
+		reverted.RevertChange(it)
+	}
+	reverted.DeleteDead()
+	if changes := Diff(old, reverted, 50); len(changes) != 0 {
+		t.Errorf("after reverting all changes: got %+v, want none", changes)
+	}
+}
+
+func TestDiffAutomation(t *testing.T) {
+	gain := func(db float64) *float64 { return &db }
+	old := newList(
+		TimeMarker{Samples: 100, Name: "Intro", Gain: gain(-6), Ramp: 2},
+		TimeMarker{Samples: 1000, Name: "Verse", Gain: gain(-3)},
+		TimeMarker{Samples: 2000, Name: "Chorus"},
+	)
+	new := newList(
+		TimeMarker{Samples: 100, Name: "Intro", Gain: gain(-6), Ramp: 1},
+		TimeMarker{Samples: 1000, Name: "Verse"},
+		TimeMarker{Samples: 2000, Name: "Chorus", Gain: gain(0)},
+	)
+	changes := Diff(old, new, 50)
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes, want 3: %+v", len(changes), changes)
+	}
+	for _, it := range changes {
+		if it.Kind != ChangeModified || it.Fields != FieldAutomation {
+			t.Errorf("got %+v, want automation changes only", it)
+		}
+		old.ApplyChange(it)
+	}
+	if changes := Diff(old, new, 50); len(changes) != 0 {
+		t.Errorf("after applying: got %+v, want none", changes)
+	}
+	*new[2].Gain = -1
+	if *old[2].Gain != 0 {
+		t.Error("applied gain is shared with the other list")
+	}
+}
+
+func TestApplyChangeRespectsLimit(t *testing.T) {
+	full := NewTimeMarkers()
+	for idx := range Limit {
+		full.AttachNewMarker(TimeMarker{Samples: idx * 10})
+	}
+	added := &TimeMarker{Samples: 99999, Name: "Extra"}
+	if full.ApplyChange(Change{Kind: ChangeAdded, New: added}) {
+		t.Error("added a marker over the limit")
+	}
+}
@@ -20,10 +20,13 @@ func NewTimeMarkers() TimeMarkers {
 
 type TimeMarker struct {
 	Samples      int    `json:"samples,omitempty"`
+	End          int    `json:"end,omitempty"` // samples where a region ends, 0 for a point marker
 	Name         string `json:"name,omitempty"`
 	isDead       bool
 	Notes        string      `json:"notes,omitempty"`
 	CategoryTags []string    `json:"category_tags,omitempty"`
+	Gain         *float64    `json:"gain,omitempty"` // target volume in dB, nil means no automation
+	Ramp         float64     `json:"ramp,omitempty"` // seconds to reach "Gain"
 	List         widget.List `json:"-"`
 	ListTags     `json:"-"`
 	EditorTags   `json:"-"`
@@ -149,11 +152,73 @@ func (t *TimeMarkers) IsFull() bool {
 	return len(*t) >= Limit
 }
 
+// Tag of markers which lost their position because it doesn't exist in the audio
+const RedactedTag = "Redacted"
+
+// Region markers come from other apps and end somewhere after "Samples"
+func (m *TimeMarker) IsRegion() bool {
+	return m.End > m.Samples
+}
+
+func (m *TimeMarker) redact() {
+	m.Samples = 0
+	m.End = 0
+	if !slices.Contains(m.CategoryTags, RedactedTag) {
+		m.CategoryTags = append(m.CategoryTags, RedactedTag)
+	}
+}
+
 func (t *TimeMarkers) SanitizeSamples(maxSamples int) {
 	for _, it := range *t {
 		if it.Samples > maxSamples {
-			it.Samples = 0
-			it.CategoryTags = append(it.CategoryTags, "Redacted")
+			it.redact()
+		}
+		it.End = min(it.End, maxSamples)
+	}
+}
+
+// Position of a marker at "samples" after shifting by "offset", false if it falls out of the audio
+func ShiftedSamples(samples, offset, maxSamples int) (int, bool) {
+	shifted := samples + offset
+	if shifted < 0 || shifted > maxSamples {
+		return 0, false
+	}
+	return shifted, true
+}
+
+// Moves all markers by "offset" samples. Markers falling out of the audio are redacted
+func (t *TimeMarkers) Shift(offset, maxSamples int) (redacted int) {
+	for _, it := range *t {
+		shifted, ok := ShiftedSamples(it.Samples, offset, maxSamples)
+		if !ok {
+			it.redact()
+			redacted++
+			continue
+		}
+		it.Samples = shifted
+		if it.End > 0 {
+			it.End = min(it.End+offset, maxSamples)
 		}
 	}
+	t.Sort()
+	return redacted
+}
+
+// Attaches markers which are not present yet, until the limit is reached
+func (t *TimeMarkers) Merge(other TimeMarkers) (added, overLimit int) {
+	for _, it := range other {
+		exists := slices.ContainsFunc(*t, func(cur *TimeMarker) bool {
+			return cur.Samples == it.Samples && cur.Name == it.Name
+		})
+		if exists {
+			continue
+		}
+		if !t.AttachNewMarker(*it) {
+			overLimit++
+			continue
+		}
+		added++
+	}
+	t.Sort()
+	return added, overLimit
 }
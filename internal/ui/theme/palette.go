@@ -85,19 +85,35 @@ var repeatPalette = palette{
 	Project:       project,
 	MarkersViewBg: rgb(0x7EB6D7),
 	Editor: editorPalette{
-		Bg:        tan,
-		SoundWave: blackRF,
-		Playhead:  white,
-		AddMarker: cyan,
-		MarkerDev: 8,
+		Bg:         tan,
+		SoundWave:  blackRF,
+		RMS:        argb(0xb3f0be60),
+		Playhead:   white,
+		AddMarker:  cyan,
+		ChannelSep: argb(0x66010101),
+		Envelope:   cyan,
+		MarkerDev:  8,
 		Grid: gridPalette{
 			Tick:    rgb(0x000000),
 			Tick5s:  white,
 			Tick10s: white,
 		},
+		Spectrogram: spectrogramPalette{
+			Low:  darkBlue,
+			Mid:  red,
+			High: mimosa,
+		},
+		Overview: overviewPalette{
+			Bg:           argb(0x33000000),
+			Wave:         blackRF,
+			Marker:       cyan,
+			Window:       argb(0x40ffffff),
+			WindowStroke: white,
+		},
 	},
-	Mimosa: mimosa,
-	Link:   rgb(0x0A69DA),
+	Mimosa:   mimosa,
+	Link:     rgb(0x0A69DA),
+	Redacted: rgb(0xB3261E),
 }
 
 var (
@@ -131,6 +147,7 @@ type palette struct {
 	Editor        editorPalette
 	Mimosa        color.NRGBA
 	Link          color.NRGBA
+	Redacted      color.NRGBA // markers which lose their position
 }
 type comboOptionStatesPalette struct {
 	Hovered comboOptionPalette
@@ -194,12 +211,32 @@ type segButtonsPalette struct {
 }
 
 type editorPalette struct {
-	SoundWave color.NRGBA
-	Bg        color.NRGBA
-	Playhead  color.NRGBA
-	Grid      gridPalette
-	AddMarker color.NRGBA
-	MarkerDev int // Color deviation for stacked markers, so they can be distinguished
+	SoundWave   color.NRGBA
+	RMS         color.NRGBA
+	Bg          color.NRGBA
+	Playhead    color.NRGBA
+	Grid        gridPalette
+	AddMarker   color.NRGBA
+	ChannelSep  color.NRGBA
+	Envelope    color.NRGBA // volume automation line
+	MarkerDev   int         // Color deviation for stacked markers, so they can be distinguished
+	Overview    overviewPalette
+	Spectrogram spectrogramPalette
+}
+
+// Gradient stops from the quietest to the loudest
+type spectrogramPalette struct {
+	Low  color.NRGBA
+	Mid  color.NRGBA
+	High color.NRGBA
+}
+
+type overviewPalette struct {
+	Bg           color.NRGBA
+	Wave         color.NRGBA
+	Marker       color.NRGBA
+	Window       color.NRGBA
+	WindowStroke color.NRGBA
 }
 
 type gridPalette struct {
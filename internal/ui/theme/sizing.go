@@ -4,6 +4,8 @@ var repeatSizing = sizing{
 	SegButtonsTopM: 30,
 	Editor: editorSizing{
 		PlayheadW:    4,
+		ChannelSepW:  1,
+		EnvelopeW:    2,
 		CreateButtMT: 85.0,
 		WaveM:        32.0,
 		Grid: gridSizing{
@@ -15,6 +17,17 @@ var repeatSizing = sizing{
 			MinTimeInterval: 100,
 		},
 		Markers: markers,
+		Overview: overviewSizing{
+			MargT:         40.0,
+			MargX:         16,
+			H:             30,
+			TickW:         2,
+			WindowStrokeW: 1,
+		},
+		Toolbar: toolbarSizing{
+			MargL: 16,
+			Gap:   8,
+		},
 	},
 }
 
@@ -25,10 +38,29 @@ type sizing struct {
 
 type editorSizing struct {
 	PlayheadW    int
+	ChannelSepW  int
+	EnvelopeW    int     // volume automation line
 	CreateButtMT float32 // create button margin top
 	WaveM        float32
 	Grid         gridSizing
 	Markers      markersSizing
+	Overview     overviewSizing
+	Toolbar      toolbarSizing
+}
+
+// In px
+type overviewSizing struct {
+	MargT         float32 // percent of wave margin
+	MargX         int
+	H             int
+	TickW         int
+	WindowStrokeW int
+}
+
+// In px
+type toolbarSizing struct {
+	MargL int
+	Gap   int
 }
 
 // In px
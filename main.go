@@ -42,6 +42,10 @@ func main() {
 		os.Exit(1)
 	}
 	repeatApp := newApp(&appState)
+	if len(os.Args) > 1 {
+		// Files opened with the app, like a bundle sent by a colleague
+		go appState.OpenOnStartup(os.Args[1])
+	}
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {